@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitlab.com/daneofmanythings/wohrdle/config"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/solver"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+)
+
+const solveHelp string = `Enter the feedback for each guess: g for correct, y for present, . for absent.
+Either "GUESS FEEDBACK", e.g. "crane gy..g", or just the feedback to play the top suggestion.
+<ctrl-d> or "quit" to stop.`
+
+// runSolve is the `wohrdle solve` subcommand. It returns the exit code.
+func runSolve(args []string) int {
+	fs := flag.NewFlagSet("solve", flag.ContinueOnError)
+	wordLen := fs.Int("len", 5, "length of the word to solve")
+	wordsPath := fs.String("words", "", "solve with the word list at `PATH`, either JSON or one word per line")
+	language := fs.String("lang", "", "solve in `LANGUAGE`: english, spanish, german or portuguese")
+	top := fs.Int("top", 5, "how many suggestions to show")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *top < 1 {
+		fmt.Fprintf(os.Stderr, "-top must be at least 1, got %d\n", *top)
+		fs.Usage()
+		return 2
+	}
+
+	cfg := loadConfig()
+	if *wordsPath == "" {
+		*wordsPath = cfg.WordsPath()
+	}
+	languages, err := loadLanguages(*wordsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load the word list: %v\n", err)
+		return 2
+	}
+	params, err := states.NewParameters(languages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load the word list: %v\n", err)
+		return 2
+	}
+	if *language != "" {
+		cfg.Defaults.Language = language
+	}
+	if err := params.ApplyDefaults(config.Defaults{Language: cfg.Defaults.Language}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	// accented letters are typed as plain ones in the game, so the solver folds them too
+	wordRepo, key := params.WordRepo, strconv.Itoa(*wordLen)
+	s := solver.New(*wordLen, wordRepo.Answers[key], wordRepo.Allowed[key], wordRepo.FoldingTable())
+	if len(s.Candidates()) == 0 {
+		fmt.Fprintf(os.Stderr, "no words of length %d\n", *wordLen)
+		return 2
+	}
+	if err := solveInteractive(s, *top, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "solve: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// solveInteractive suggests guesses and reads back their feedback until the word is found.
+func solveInteractive(s *solver.Solver, top int, in io.Reader, out io.Writer) error {
+	fmt.Fprintln(out, solveHelp)
+	scanner := bufio.NewScanner(in)
+	for {
+		candidates := s.Candidates()
+		if len(candidates) == 1 {
+			fmt.Fprintf(out, "The word is %s!\n", candidates[0])
+			return nil
+		}
+		suggestions, err := s.Rank(top)
+		if err != nil {
+			return err
+		}
+		printSuggestions(out, candidates, suggestions)
+
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		line := strings.Fields(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] == "quit" || line[0] == "q" {
+			return nil
+		}
+
+		guess, pattern := suggestions[0].Word, line[0]
+		if len(line) > 1 {
+			guess, pattern = line[0], line[1]
+		}
+		feedback, err := solver.ParseFeedback(pattern)
+		if err == nil {
+			err = s.Apply(guess, feedback)
+		}
+		if errors.Is(err, solver.ErrNoCandidates) {
+			fmt.Fprintln(out, "No words match that. Check the feedback and try again.")
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+		if !slices.ContainsFunc(feedback, func(state engine.CellState) bool { return state != engine.CORRECT }) {
+			fmt.Fprintf(out, "Solved with %s!\n", strings.ToUpper(guess))
+			return nil
+		}
+	}
+}
+
+func printSuggestions(out io.Writer, candidates []string, suggestions []solver.Suggestion) {
+	fmt.Fprintf(out, "%d words left", len(candidates))
+	if len(candidates) <= 10 {
+		fmt.Fprintf(out, ": %s", strings.Join(candidates, " "))
+	}
+	fmt.Fprintln(out)
+	for i, sug := range suggestions {
+		marker := ""
+		if sug.Candidate {
+			marker = " *"
+		}
+		fmt.Fprintf(out, "  %d. %s  %.2f bits, ~%.1f left%s\n", i+1, sug.Word, sug.Entropy, sug.ExpectedRemaining, marker)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/config"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/multiplayer"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
+	"gitlab.com/daneofmanythings/wohrdle/render"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+// runServe is the `wohrdle serve` subcommand. It returns the exit code.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":7777", "listen on `HOST:PORT`")
+	numPlayers := fs.Int("players", 2, "start a round once `N` players are ready")
+	wordsPath := fs.String("words", "", "play with the word list at `PATH`, either JSON or one word per line")
+	wordLen := fs.Int("len", 0, "play words of `N` letters")
+	numGuesses := fs.Int("guesses", 0, "allow `N` guesses")
+	numFails := fs.Int("fails", 0, "allow `N` failed words")
+	hardMode := fs.Bool("hard", false, "play in hard-mode")
+	language := fs.String("lang", "", "play in `LANGUAGE`: english, spanish, german or portuguese")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	// only the flags that were given change a setting
+	flagged := config.Defaults{}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "len":
+			flagged.WordLen = wordLen
+		case "guesses":
+			flagged.NumGuesses = numGuesses
+		case "fails":
+			flagged.NumFails = numFails
+		case "hard":
+			flagged.HardMode = hardMode
+		case "lang":
+			flagged.Language = language
+		}
+	})
+
+	cfg := loadConfig()
+	if *wordsPath == "" {
+		*wordsPath = cfg.WordsPath()
+	}
+	languages, err := loadLanguages(*wordsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load the word list: %v\n", err)
+		return 2
+	}
+	params, err := states.NewParameters(languages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load the word list: %v\n", err)
+		return 2
+	}
+	if err := params.ApplyDefaults(cfg.Defaults); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid defaults in the config:\n%v\n", err)
+		return 2
+	}
+	if err := params.ApplyDefaults(flagged); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags:\n%v\nsee wohrdle serve --help\n", err)
+		return 2
+	}
+	// every round is a single board with a target drawn like any other game
+	params.Fields[states.FIELD_DAILY].Value = states.FALSE
+	params.Fields[states.FIELD_BOARDS].Value = 1
+	params.Fields[states.FIELD_ABSURD].Value = states.FALSE
+
+	server := &multiplayer.Server{
+		MinPlayers: *numPlayers,
+		Log:        os.Stdout,
+		NewRound: func() (puzzle.Code, engine.Config, error) {
+			code := params.NextPuzzle()
+			cfg, err := code.Config(params.Answers(), params.ValidWords())
+			cfg.Alphabet = params.WordRepo.Letters()
+			// accents always fold so guesses typed either way are taken
+			cfg.Folding = params.WordRepo.FoldingTable()
+			return code, cfg, err
+		},
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not listen: %v\n", err)
+		return 1
+	}
+	fmt.Printf("serving versus games on %s for %d players\n", l.Addr(), max(*numPlayers, 1))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		<-signals
+		server.Close()
+	}()
+	if err := server.Serve(l); err != nil {
+		fmt.Fprintf(os.Stderr, "serve: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runJoin is the `wohrdle join` subcommand. It returns the exit code.
+func runJoin(args []string) int {
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	name := fs.String("name", os.Getenv("USER"), "play as `NAME`")
+	wordsPath := fs.String("words", "", "play with the word list at `PATH`, the same the server plays with")
+	shareTo := fs.String("share", "clipboard", "where [s]hare puts results: clipboard, stdout (printed after exit) or a file `PATH`")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: wohrdle join HOST:PORT [flags]")
+		fs.PrintDefaults()
+	}
+	// the address can come before the flags
+	addr := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		addr, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if addr == "" {
+		addr = fs.Arg(0)
+	}
+	if addr == "" {
+		fs.Usage()
+		return 2
+	}
+
+	cfg := loadConfig()
+	if *wordsPath == "" {
+		*wordsPath = cfg.WordsPath()
+	}
+	languages, err := loadLanguages(*wordsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load the word list: %v\n", err)
+		return 2
+	}
+	dataDir, err := utils.DataDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not find a data directory: %v\n", err)
+		return 1
+	}
+	themes := loadThemes(cfg)
+	// the theme, keyboard and accent folding of the last game are kept. the server picks the rest
+	parameters := loadParameters(cfg, languages, themes, filepath.Join(dataDir, settingsFileName))
+
+	conn, err := multiplayer.Dial(addr, *name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not join %s: %v\n", addr, err)
+		return 1
+	}
+	defer conn.Close()
+
+	a := &app{
+		parameters: parameters,
+		shareTo:    *shareTo,
+		renderer:   render.NewRenderer(themes),
+	}
+	a.screen, err = render.CreateScreen()
+	if err != nil {
+		panic(err)
+	}
+	defer a.screen.Fini()
+	a.postSignals()
+
+	go func() {
+		for {
+			msg, err := conn.Receive()
+			a.screen.PostEvent(states.NewEventMessage(msg, err))
+			if err != nil {
+				return
+			}
+		}
+	}()
+	a.runVersus(states.NewVersus(conn, parameters))
+	conn.Close()
+	a.quit()
+	return 0
+}
+
+// runVersus plays against the other players until the player leaves.
+func (a *app) runVersus(v *states.Versus) {
+	a.renderer.UseTheme(v.Parameters.Theme())
+	for {
+		// the versus loop
+		a.renderer.DrawVersus(a.screen, v)
+		switch ev := a.screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			a.screen.Sync()
+		case *tcell.EventInterrupt:
+			return
+		case *states.EventMessage:
+			if ev.Err != nil {
+				v.Disconnect(ev.Err)
+				continue
+			}
+			v.HandleMessage(ev.Msg)
+		case *tcell.EventKey:
+			if shouldExit := v.HandleEventKey(ev); shouldExit {
+				return
+			}
+			if gs := v.Game; gs != nil && gs.Sharing {
+				gs.Sharing = false
+				a.share(gs)
+			}
+		case *tcell.EventMouse:
+			if v.Game != nil {
+				x, y := ev.Position()
+				letter, _ := a.renderer.KeyAt(a.screen, v.Game, x, y)
+				v.HandleEventMouse(ev, letter)
+			}
+		case *tcell.EventPaste:
+			v.HandleEventPaste(ev)
+		default:
+			// nothing
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+	"gitlab.com/daneofmanythings/wohrdle/wordlist"
+)
+
+const wordlistUsage string = `usage: wohrdle wordlist <command> [flags]
+
+commands:
+  build    build a word list from a raw dictionary
+  stats    print how many words there are of each length`
+
+// runWordlist is the `wohrdle wordlist` subcommand. It returns the exit code.
+func runWordlist(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, wordlistUsage)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "build":
+		err = runWordlistBuild(args[1:])
+	case "stats":
+		err = runWordlistStats(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Println(wordlistUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown wordlist command %q\n%s\n", args[0], wordlistUsage)
+		return 2
+	}
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wordlist %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func runWordlistBuild(args []string) error {
+	fs := flag.NewFlagSet("wordlist build", flag.ContinueOnError)
+	in := fs.String("in", "", "raw word list to read, one word and optional frequency per line. `PATH` or - for stdin")
+	out := fs.String("out", "-", "where to write the word list. `PATH` ending in .json for JSON, otherwise text. - for stdout")
+	filter := wordlist.Filter{}
+	fs.IntVar(&filter.MinLen, "min-len", 1, "shortest word to keep")
+	fs.IntVar(&filter.MaxLen, "max-len", 0, "longest word to keep. 0 for no limit")
+	fs.BoolVar(&filter.ExcludeProperNouns, "exclude-proper-nouns", true, "drop words with upper-case letters")
+	fs.BoolVar(&filter.ExcludeApostrophes, "exclude-apostrophes", true, "drop words with apostrophes instead of removing the apostrophe")
+	fs.BoolVar(&filter.ExcludeAccented, "exclude-accented", true, "drop words with letters outside of a-z")
+	fs.StringVar(&filter.Alphabet, "alphabet", "", "keep only words spelled with `LETTERS` instead of a-z, and write them to JSON lists")
+	fold := fs.String("fold", "", "let the accented letters of `PAIRS` like áa,ée be typed as the plain letter that follows them. JSON lists only")
+	fs.IntVar(&filter.MinFrequency, "min-freq", 0, "drop words used less often than this. words without a frequency count as 0")
+	deny := fs.String("deny", "", "drop the words listed in the file at `PATH`")
+	answers := fs.String("answers", "", "draw targets only from the words listed in the file at `PATH`, the rest are only allowed as guesses. JSON lists only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return fmt.Errorf("-in is required")
+	}
+	filter.Alphabet = strings.ToUpper(filter.Alphabet)
+	repo := utils.WordRepository{Alphabet: filter.Alphabet}
+	if *fold != "" {
+		var err error
+		if repo.Folding, err = wordlist.ParseFolding(*fold); err != nil {
+			return err
+		}
+	}
+
+	entries, err := readEntries(*in)
+	if err != nil {
+		return err
+	}
+	if *deny != "" {
+		f, err := os.Open(*deny)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if filter.Deny, err = wordlist.ReadWordSet(f); err != nil {
+			return fmt.Errorf("%s: %w", *deny, err)
+		}
+	}
+	words := wordlist.Build(entries, filter)
+	if len(words) == 0 {
+		return fmt.Errorf("no words left after filtering %s", *in)
+	}
+	repo.Words = words
+	if *answers != "" {
+		f, err := os.Open(*answers)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		pool, err := wordlist.ReadWordSet(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *answers, err)
+		}
+		if repo.Answers = wordlist.Answers(words, pool); len(repo.Answers) == 0 {
+			return fmt.Errorf("none of the answers in %s are left after filtering %s", *answers, *in)
+		}
+		repo.Words, repo.Allowed = nil, words
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if strings.EqualFold(filepath.Ext(*out), ".json") {
+		return wordlist.WriteJSON(w, repo)
+	}
+	return wordlist.WriteText(w, words)
+}
+
+func readEntries(path string) ([]wordlist.Entry, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	entries, err := wordlist.Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func runWordlistStats(args []string) error {
+	fs := flag.NewFlagSet("wordlist stats", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: wohrdle wordlist stats [PATH]\n\nprints the embedded word list when PATH is omitted")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected at most one word list, got %d", fs.NArg())
+	}
+
+	wordRepo, err := loadWordRepo(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return wordlist.WriteStats(os.Stdout, wordRepo)
+}
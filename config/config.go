@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const fileName string = "config.json"
+
+// Config is the user's config file. Every field is optional.
+type Config struct {
+	Words    string              `json:"words"`    // path to a custom word list. relative paths are from the config dir
+	Defaults Defaults            `json:"defaults"` // settings the menu starts with
+	Themes   []Theme             `json:"themes"`   // picked from the menu along with the built-in ones
+	Keys     map[string][]string `json:"keys"`     // rebinds actions, by name, to keys
+
+	path string
+}
+
+// Defaults are settings of the menu. Only the ones that are set are used.
+type Defaults struct {
+	WordLen     *int    `json:"word_length,omitempty"`
+	NumGuesses  *int    `json:"num_guesses,omitempty"`
+	NumFails    *int    `json:"num_failed_words,omitempty"`
+	HardMode    *bool   `json:"hard_mode,omitempty"`
+	Daily       *bool   `json:"daily,omitempty"`
+	NumBoards   *int    `json:"boards,omitempty"`
+	Absurd      *bool   `json:"absurd,omitempty"`
+	NumHints    *int    `json:"hints,omitempty"`
+	Mode        *string `json:"mode,omitempty"`
+	TimeLimit   *int    `json:"time_limit,omitempty"` // in seconds
+	Theme       *string `json:"theme,omitempty"`
+	Keyboard    *string `json:"keyboard,omitempty"`     // the layout the seen letters are shown in
+	Language    *string `json:"language,omitempty"`     // the embedded word list to play with
+	FoldAccents *bool   `json:"fold_accents,omitempty"` // accented letters are typed as plain ones
+}
+
+// Theme is a user defined theme. It starts from the built-in theme Base, "default" when
+// empty, and replaces the styles that are set.
+type Theme struct {
+	Name string `json:"name"`
+	Base string `json:"base"`
+
+	// the letters of the grid, by their score
+	Typed   *Style `json:"typed"`
+	Correct *Style `json:"correct"`
+	Partial *Style `json:"partial"`
+	Used    *Style `json:"used"`
+	UsedKey *Style `json:"used_key"` // a key of the keyboard whose letter is not in the word
+
+	// the message below the grid, by the state of the game
+	Active  *Style `json:"active"`
+	Victory *Style `json:"victory"`
+	Loss    *Style `json:"loss"`
+
+	Grid   *Style `json:"grid"`
+	Solved *Style `json:"solved"` // the grid of a solved board
+	Faded  *Style `json:"faded"`  // help text and letters revealed by hints
+}
+
+// Style is a terminal style. Colors are names like "orange" or hex like "#ff8800".
+type Style struct {
+	Fg        string `json:"fg"`
+	Bg        string `json:"bg"`
+	Bold      bool   `json:"bold"`
+	Underline bool   `json:"underline"`
+	Reverse   bool   `json:"reverse"`
+}
+
+// Path is where the config is read from inside dir.
+func Path(dir string) string {
+	return filepath.Join(dir, fileName)
+}
+
+// Load reads the config at path. A missing file is the empty config.
+func Load(path string) (Config, error) {
+	cfg := Config{path: path}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	// misspelled keys would otherwise be ignored without a word
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if dec.More() {
+		return cfg, fmt.Errorf("%s: unexpected data after the config", path)
+	}
+	return cfg, nil
+}
+
+// WordsPath is the custom word list with relative paths resolved against the config's dir.
+// It is empty when no word list is configured.
+func (c Config) WordsPath() string {
+	if c.Words == "" || filepath.IsAbs(c.Words) {
+		return c.Words
+	}
+	return filepath.Join(filepath.Dir(c.path), c.Words)
+}
+
+// LoadDefaults reads settings written by WriteDefaults. A missing file sets nothing.
+func LoadDefaults(path string) (Defaults, error) {
+	d := Defaults{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+	if err := json.Unmarshal(b, &d); err != nil {
+		return d, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// WriteDefaults stores d at path, replacing whatever was there.
+func WriteDefaults(path string, d Defaults) error {
+	b, err := json.MarshalIndent(d, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), fileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WordsPath() != "" {
+		t.Fatalf("unexpected words path. got=%s", cfg.WordsPath())
+	}
+}
+
+func TestLoadWordsPath(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{"relative", `{"words": "lists/words.txt"}`, filepath.Join(dir, "lists/words.txt")},
+		{"absolute", `{"words": "/usr/share/words.txt"}`, "/usr/share/words.txt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.WriteFile(Path(dir), []byte(tc.json), 0o644)
+			cfg, err := Load(Path(dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.WordsPath() != tc.expected {
+				t.Fatalf("unexpected words path. got=%s, expected=%s", cfg.WordsPath(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestLoadMalformed(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+	}{
+		{"wrong type", `{"words": 5}`},
+		{"unknown key", `{"defaults": {"word_lenght": 5}}`},
+		{"trailing data", `{"words": "words.txt"} {}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(Path(dir), []byte(tc.config), 0o644)
+			if _, err := Load(Path(dir)); err == nil {
+				t.Fatal("a malformed config was accepted")
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(Path(dir), []byte(`{"defaults": {"word_length": 6, "hard_mode": true, "mode": "survival"}}`), 0o644)
+	cfg, err := Load(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := cfg.Defaults
+	if d.WordLen == nil || *d.WordLen != 6 || d.HardMode == nil || !*d.HardMode || d.Mode == nil || *d.Mode != "survival" {
+		t.Fatalf("the defaults were not read. got=%+v", d)
+	}
+	if d.NumGuesses != nil || d.Daily != nil {
+		t.Fatalf("settings missing from the config were set. got=%+v", d)
+	}
+}
+
+func TestWriteDefaultsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	wordLen, hints := 7, 2
+	if err := WriteDefaults(path, Defaults{WordLen: &wordLen, NumHints: &hints}); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadDefaults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.WordLen == nil || *d.WordLen != wordLen || d.NumHints == nil || *d.NumHints != hints || d.Mode != nil {
+		t.Fatalf("round trip failed. got=%+v", d)
+	}
+}
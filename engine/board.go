@@ -0,0 +1,338 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+// Board is one target and the rows guessed against it. Every guess of a GameSession is
+// played on each of its boards until the board is solved, at which point it locks.
+type Board struct {
+	Grid      [][]Cell
+	SeenChars []Cell
+
+	targetWordAsRunes  []rune
+	targetWordAsString string
+
+	curIdx int
+	solved bool
+
+	// letters revealed by hints, either at their position or only as being in the word
+	revealed map[int]rune
+	hinted   []rune
+
+	// candidates are the words still consistent with the feedback of an adversarial board.
+	// nil when the target was chosen up front
+	candidates []string
+
+	// accented letters and the plain letter they are typed as. letters are compared folded,
+	// while scored rows show the letters of the target
+	folding map[rune]rune
+}
+
+func newBoard(numRows int, target string, alphabet []rune, folding map[rune]rune) *Board {
+	b := &Board{
+		SeenChars: NewSeenCharRecord(alphabet),
+		revealed:  map[int]rune{},
+		folding:   folding,
+	}
+	b.Grid = make([][]Cell, numRows)
+	for i := range b.Grid {
+		b.Grid[i] = []Cell{}
+	}
+	b.setTarget(target)
+	return b
+}
+
+// newAdversarialBoard makes a board without a target. Each guess is answered with the
+// feedback that keeps the most words in play. see narrowCandidates
+func newAdversarialBoard(numRows int, words []string, alphabet []rune, folding map[rune]rune) *Board {
+	b := newBoard(numRows, "", alphabet, folding)
+	b.setCandidates(words)
+	return b
+}
+
+func (b *Board) setCandidates(words []string) {
+	b.candidates = make([]string, len(words))
+	for i, word := range words {
+		b.candidates[i] = strings.ToUpper(word)
+	}
+	slices.Sort(b.candidates)
+	// any candidate will do as the target until the first guess narrows them down
+	b.setTarget(b.candidates[0])
+}
+
+func (b *Board) setTarget(word string) {
+	b.targetWordAsString = strings.ToUpper(word)
+	b.targetWordAsRunes = utils.RuneSliceToUpper([]rune(word))
+}
+
+// Target is the upper-cased word of the board. Frontends should only show it once the game is over.
+func (b *Board) Target() string {
+	return b.targetWordAsString
+}
+
+// IsSolved reports whether the board has been locked by guessing its target.
+func (b *Board) IsSolved() bool {
+	return b.solved
+}
+
+// RowsUsed is the number of rows that have been scored on the board.
+func (b *Board) RowsUsed() int {
+	return b.curIdx
+}
+
+func (b *Board) reset() {
+	b.curIdx = 0
+	b.solved = false
+	clear(b.revealed)
+	b.hinted = nil
+	for i := range b.Grid {
+		b.Grid[i] = nil
+	}
+	for i := range b.SeenChars {
+		b.SeenChars[i].SetState(DEFAULT)
+	}
+}
+
+// helper function for debugging
+func (b *Board) getCurrentRow() []Cell {
+	return b.Grid[b.curIdx]
+}
+
+func (b *Board) curGuessAsUpperString() string {
+	var word string
+	for _, cell := range b.Grid[b.curIdx] {
+		word += utils.RuneToAlpha(cell.Char)
+	}
+	return word
+}
+
+func (b *Board) isHardModeSatisfied() bool {
+	// Positions revealed by hints are as good as correct
+	for pos, r := range b.revealed {
+		if !b.sameLetter(b.Grid[b.curIdx][pos].Char, r) {
+			return false
+		}
+	}
+
+	// Can't fail on the first guess
+	if b.curIdx == 0 {
+		return true
+	}
+
+	// Need this to detect missed PARTIALS
+	countByRune := b.countMapForCurrRow()
+
+	// Making things easier to reason about in the code
+	prevRow := &b.Grid[b.curIdx-1]
+	currRow := &b.Grid[b.curIdx]
+	// First pass to see if any previously correct are missing and to update the countMap
+	// for the second pass
+	for i := range *prevRow {
+		// Making things easier to reason about in the code
+		prevRowCell := (*prevRow)[i]
+		currRowCell := (*currRow)[i]
+		if prevRowCell.GetState() != CORRECT {
+			continue
+		}
+		// Since the cell is correct, the chars should match
+		if !b.sameLetter(prevRowCell.Char, currRowCell.Char) {
+			return false
+		}
+		// they matched, so decrement the countMap
+		countByRune[b.fold(currRowCell.Char)] -= 1
+	}
+
+	// Second pass to catch any missing PARTIALS. looking at the cells of the previous row
+	// in relation to how many are left in the countMap of the current row
+	for _, cell := range *prevRow {
+		// dont care if it isnt a PARTIAL
+		if cell.GetState() != PARTIAL {
+			continue
+		}
+		if countByRune[b.fold(cell.Char)] < 1 {
+			// We found a partial that isnt represented in the current row.
+			// IT HAS TO BE REPRESENTED
+			return false
+		}
+		// it is represented, so we decrement the count for that PARTIAL
+		countByRune[b.fold(cell.Char)] -= 1
+	}
+
+	return true
+}
+
+// finalizeCurRow scores the current row and moves on to the next. The board locks
+// when the row was its target.
+func (b *Board) finalizeCurRow() {
+	// This populates the cells in the current row with thier correct stylings for the renderer
+	row := b.Grid[b.curIdx]
+	guess := make([]rune, len(row))
+	for i := range row {
+		guess[i] = row[i].Char
+	}
+
+	if b.candidates != nil {
+		b.narrowCandidates(guess)
+	}
+	isWinner := b.IsWinner()
+	for i, state := range b.score(guess, b.targetWordAsRunes) {
+		row[i].SetState(state)
+	}
+	b.showTargetLetters(row)
+	b.updateSeenChars(row)
+	b.curIdx += 1
+	b.solved = isWinner
+}
+
+// narrowCandidates groups the candidates by the feedback the guess would get against each
+// of them and keeps the largest group. Every candidate left scores the guess the same way,
+// so the first one becomes the target and the row is scored as usual. Ties go to the
+// feedback revealing the least, so a guess is only ever correct once it is the last word left.
+func (b *Board) narrowCandidates(guess []rune) {
+	buckets := map[string][]string{}
+	patterns := map[string][]CellState{}
+	for _, candidate := range b.candidates {
+		pattern := b.score(guess, []rune(candidate))
+		key := fmt.Sprint(pattern)
+		buckets[key] = append(buckets[key], candidate)
+		patterns[key] = pattern
+	}
+
+	best := ""
+	for key := range buckets {
+		if best == "" || isBetterBucket(len(buckets[key]), patterns[key], key, len(buckets[best]), patterns[best], best) {
+			best = key
+		}
+	}
+	b.candidates = buckets[best]
+	b.setTarget(b.candidates[0])
+}
+
+// isBetterBucket prefers bigger buckets, then feedback revealing less, then the lowest key
+// so the choice never depends on map order.
+func isBetterBucket(size int, pattern []CellState, key string, bestSize int, bestPattern []CellState, bestKey string) bool {
+	if size != bestSize {
+		return size > bestSize
+	}
+	if revealed(pattern) != revealed(bestPattern) {
+		return revealed(pattern) < revealed(bestPattern)
+	}
+	return key < bestKey
+}
+
+// revealed weighs how much a feedback pattern gives away.
+func revealed(pattern []CellState) int {
+	n := 0
+	for _, state := range pattern {
+		switch state {
+		case CORRECT:
+			n += 2
+		case PARTIAL:
+			n += 1
+		}
+	}
+	return n
+}
+
+// Candidates are the words an adversarial board could still be. nil for a board with a fixed target.
+func (b *Board) Candidates() []string {
+	return slices.Clone(b.candidates)
+}
+
+// updateSeenChars records the best known state of each letter in a scored row. A letter is
+// only USED if it has never been anything better.
+func (b *Board) updateSeenChars(row []Cell) {
+	for _, cell := range row {
+		idx := b.seenIndex(cell.Char)
+		if idx == -1 {
+			continue
+		}
+		seen := &b.SeenChars[idx]
+		switch cell.GetState() {
+		case CORRECT:
+			seen.SetState(CORRECT)
+		case PARTIAL:
+			if seen.GetState() != CORRECT {
+				seen.SetState(PARTIAL)
+			}
+		case USED:
+			if seen.GetState() == DEFAULT {
+				seen.SetState(USED)
+			}
+		}
+	}
+}
+
+// seenIndex is where r is in the seen char tracker, or -1 when it is not in the alphabet.
+// Accented letters are tracked by the letter they fold to.
+func (b *Board) seenIndex(r rune) int {
+	r = b.fold(r)
+	return slices.IndexFunc(b.SeenChars, func(c Cell) bool { return c.Char == r })
+}
+
+func (b *Board) fold(r rune) rune {
+	return Fold(b.folding, r)
+}
+
+// sameLetter reports whether r1 and r2 are the same letter once folded.
+func (b *Board) sameLetter(r1, r2 rune) bool {
+	return b.fold(r1) == b.fold(r2)
+}
+
+// score is Score with both words folded.
+func (b *Board) score(guess, target []rune) []CellState {
+	return Score(FoldRunes(b.folding, guess), FoldRunes(b.folding, target))
+}
+
+// showTargetLetters swaps the letters of a scored row for the accented letters of the target
+// they matched. A PARTIAL takes the first letter of the target it could be that is not
+// already accounted for.
+func (b *Board) showTargetLetters(row []Cell) {
+	if b.folding == nil {
+		return
+	}
+	taken := make([]bool, len(b.targetWordAsRunes))
+	for i := range row {
+		if row[i].GetState() == CORRECT {
+			row[i].Char = b.targetWordAsRunes[i]
+			taken[i] = true
+		}
+	}
+	for i := range row {
+		if row[i].GetState() != PARTIAL {
+			continue
+		}
+		for j, r := range b.targetWordAsRunes {
+			if !taken[j] && b.sameLetter(row[i].Char, r) {
+				row[i].Char = r
+				taken[j] = true
+				break
+			}
+		}
+	}
+}
+
+func (b *Board) countMapForTargetWord() map[rune]int {
+	return countMap(b.targetWordAsRunes)
+}
+
+func (b *Board) countMapForCurrRow() map[rune]int {
+	return countMap(FoldRunes(b.folding, []rune(b.curGuessAsUpperString())))
+}
+
+func (b *Board) IsWinner() bool {
+	if len(b.targetWordAsRunes) != len(b.Grid[b.curIdx]) {
+		panic("len of word and guess do not match")
+	}
+	for i := range b.targetWordAsRunes {
+		if !b.sameLetter(b.targetWordAsRunes[i], b.Grid[b.curIdx][i].Char) {
+			return false
+		}
+	}
+	return true
+}
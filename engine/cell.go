@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"slices"
+
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+type CellState int
+
+const (
+	DEFAULT CellState = iota
+	CORRECT
+	PARTIAL
+	USED
+)
+
+var cellStates []CellState = []CellState{DEFAULT, CORRECT, PARTIAL, USED}
+
+type Cell struct {
+	Char  rune
+	state CellState
+}
+
+func NewCell(r rune, state CellState) Cell {
+	c := Cell{Char: r}
+	c.SetState(state)
+	return c
+}
+
+func (c *Cell) isEqualTo(other Cell) bool {
+	return c.Char == other.Char && c.state == other.state
+}
+
+func (c *Cell) SetState(state CellState) {
+	if !slices.Contains(cellStates, state) {
+		return
+	}
+	c.state = state
+}
+
+func (c *Cell) GetState() CellState {
+	return c.state
+}
+
+// AllRunes is the alphabet of a game that does not give one.
+var AllRunes []rune = []rune(utils.DEFAULT_ALPHABET)
+
+// NewSeenCharRecord tracks every letter of alphabet, in its order.
+func NewSeenCharRecord(alphabet []rune) []Cell {
+	seenCharRecord := []Cell{}
+	for _, r := range alphabet {
+		seenCharRecord = append(seenCharRecord, Cell{r, DEFAULT})
+	}
+	return seenCharRecord
+}
@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+type HintKind int
+
+const (
+	HINT_POSITION HintKind = iota // reveals the letter at a position not yet known to be correct
+	HINT_LETTER                   // reveals a letter of the word not yet seen
+	HINT_COUNT                    // counts the words still consistent with the feedback
+)
+
+var (
+	// ErrNoHints is returned when asking for a hint with none left in the budget.
+	ErrNoHints = errors.New("no hints left")
+	// ErrNothingToReveal is returned when every board already knows what the hint would reveal. It costs nothing.
+	ErrNothingToReveal = errors.New("nothing left to reveal")
+)
+
+// Hint is a revealed hint. Only the fields of its kind are set.
+type Hint struct {
+	Kind   HintKind `json:"kind"`
+	Turn   int      `json:"turn"` // the number of guesses scored when the hint was taken
+	Board  int      `json:"board"`
+	Pos    int      `json:"pos,omitempty"`
+	Letter rune     `json:"letter,omitempty"`
+	Count  int      `json:"count,omitempty"`
+}
+
+// Hint reveals something about the first unsolved board it can and takes it from the hint budget.
+func (gs *GameSession) Hint(kind HintKind) (Hint, error) {
+	if gs.state != ACTIVE {
+		return Hint{}, ErrGameOver
+	}
+	if gs.HintsLeft < 1 {
+		return Hint{}, ErrNoHints
+	}
+
+	hint := Hint{Kind: kind, Turn: gs.curIdx}
+	found := false
+	for i, b := range gs.Boards {
+		if b.solved {
+			continue
+		}
+		hint.Board = i
+		switch kind {
+		case HINT_POSITION:
+			hint.Pos, hint.Letter, found = b.revealPosition()
+		case HINT_LETTER:
+			hint.Letter, found = b.revealLetter()
+		case HINT_COUNT:
+			hint.Count, found = b.countCandidates(gs.config.Answers), true
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return Hint{}, ErrNothingToReveal
+	}
+
+	gs.HintsLeft -= 1
+	gs.hints = append(gs.hints, hint)
+	return hint, nil
+}
+
+// HintsUsed is how much of the hint budget has been spent.
+func (gs *GameSession) HintsUsed() int {
+	return gs.config.NumHints - gs.HintsLeft
+}
+
+// Hints are the hints taken so far, in order.
+func (gs *GameSession) Hints() []Hint {
+	return slices.Clone(gs.hints)
+}
+
+// Revealed is the letter a hint revealed at pos, if any.
+func (b *Board) Revealed(pos int) (rune, bool) {
+	r, ok := b.revealed[pos]
+	return r, ok
+}
+
+// knownPositions are the positions already scored correct or revealed by a hint.
+func (b *Board) knownPositions() map[int]bool {
+	known := map[int]bool{}
+	for pos := range b.revealed {
+		known[pos] = true
+	}
+	for _, row := range b.Grid[:b.curIdx] {
+		for i, cell := range row {
+			if cell.GetState() == CORRECT {
+				known[i] = true
+			}
+		}
+	}
+	return known
+}
+
+// revealPosition reveals the leftmost letter of the target not yet known to be correct.
+func (b *Board) revealPosition() (int, rune, bool) {
+	known := b.knownPositions()
+	for i, r := range b.targetWordAsRunes {
+		if known[i] {
+			continue
+		}
+		b.revealed[i] = r
+		b.keepCandidates(func(candidate []rune) bool { return b.sameLetter(candidate[i], r) })
+		b.updateSeenChars([]Cell{{Char: r, state: CORRECT}})
+		return i, r, true
+	}
+	return 0, 0, false
+}
+
+// revealLetter reveals the first letter of the target that has not been seen yet.
+func (b *Board) revealLetter() (rune, bool) {
+	for _, r := range b.targetWordAsRunes {
+		idx := b.seenIndex(r)
+		if idx != -1 && b.SeenChars[idx].GetState() != DEFAULT {
+			continue
+		}
+		if b.hasLetter(b.hinted, r) {
+			continue
+		}
+		b.hinted = append(b.hinted, r)
+		b.keepCandidates(func(candidate []rune) bool { return b.hasLetter(candidate, r) })
+		b.updateSeenChars([]Cell{{Char: r, state: PARTIAL}})
+		return r, true
+	}
+	return 0, false
+}
+
+// keepCandidates narrows the candidates of an adversarial board so it stays true to a hint.
+// The target already agrees with the hint, so it remains the first candidate.
+func (b *Board) keepCandidates(keep func(candidate []rune) bool) {
+	if b.candidates == nil {
+		return
+	}
+	b.candidates = slices.DeleteFunc(b.candidates, func(candidate string) bool {
+		return !keep([]rune(candidate))
+	})
+}
+
+// countCandidates counts the answers that agree with every scored row and hint of the board.
+func (b *Board) countCandidates(answers []string) int {
+	pool := b.candidates
+	if pool == nil {
+		pool = answers
+	}
+	count := 0
+	for _, word := range pool {
+		candidate := []rune(strings.ToUpper(word))
+		if len(candidate) == len(b.targetWordAsRunes) && b.isConsistent(candidate) {
+			count += 1
+		}
+	}
+	return count
+}
+
+// isConsistent reports whether candidate could still be the target given what the board shows.
+func (b *Board) isConsistent(candidate []rune) bool {
+	for _, row := range b.Grid[:b.curIdx] {
+		guess := make([]rune, len(row))
+		for i := range row {
+			guess[i] = row[i].Char
+		}
+		for i, state := range b.score(guess, candidate) {
+			if row[i].GetState() != state {
+				return false
+			}
+		}
+	}
+	for pos, r := range b.revealed {
+		if !b.sameLetter(candidate[pos], r) {
+			return false
+		}
+	}
+	for _, r := range b.hinted {
+		if !b.hasLetter(candidate, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLetter reports whether word has r in it once folded.
+func (b *Board) hasLetter(word []rune, r rune) bool {
+	return slices.ContainsFunc(word, func(c rune) bool { return b.sameLetter(c, r) })
+}
@@ -0,0 +1,63 @@
+package engine
+
+import "slices"
+
+// Score grades a guess against a target the same way a submitted row is graded.
+// Both slices are expected to be upper-cased and of equal length.
+func Score(guess, target []rune) []CellState {
+	if len(guess) != len(target) {
+		panic("len of word and guess do not match")
+	}
+	result := make([]CellState, len(guess))
+
+	countByRune := countMap(target) // This is to track repeat letters from ISSUE#1
+	// First pass
+	for i := range guess {
+		if guess[i] == target[i] {
+			countByRune[guess[i]] -= 1
+			result[i] = CORRECT
+		} else if slices.Contains(target, guess[i]) {
+			result[i] = PARTIAL
+		} else {
+			result[i] = USED
+		}
+	}
+	// Second pass to remove potential false positives of PARTIALS when they have
+	// all been correctly guessed by comparing the remaining unfound CORRECTS
+	for i := range guess {
+		if result[i] != PARTIAL {
+			continue
+		}
+		// We know it is a PARTIAL
+		if countByRune[guess[i]] < 1 {
+			result[i] = USED
+		}
+		countByRune[guess[i]] -= 1
+	}
+	return result
+}
+
+func countMap(rs []rune) map[rune]int {
+	countByRune := map[rune]int{}
+	for i := range rs {
+		countByRune[rs[i]] += 1
+	}
+	return countByRune
+}
+
+// Fold is the letter r is typed as, which is r itself unless folding gives it a plain letter.
+func Fold(folding map[rune]rune, r rune) rune {
+	if plain, ok := folding[r]; ok {
+		return plain
+	}
+	return r
+}
+
+// FoldRunes folds every rune of rs, see Fold.
+func FoldRunes(folding map[rune]rune, rs []rune) []rune {
+	folded := make([]rune, len(rs))
+	for i, r := range rs {
+		folded[i] = Fold(folding, r)
+	}
+	return folded
+}
@@ -0,0 +1,455 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type GameState int
+
+const (
+	ACTIVE GameState = iota
+	VICTORY
+	LOSS
+	TIMEOUT // the clock of a timed game ran out. the engine has no clock, see TimeOut
+)
+
+var gameStates = []GameState{ACTIVE, VICTORY, LOSS, TIMEOUT}
+
+var (
+	// ErrWrongLength is returned when a guess does not fill the row. It costs nothing.
+	ErrWrongLength = errors.New("guess does not match the word length")
+	// ErrInvalidWord is returned when a guess is not in the word list. It costs a failed entry.
+	ErrInvalidWord = errors.New("word not in word list")
+	// ErrHardModeViolated is returned when a guess ignores revealed hints. It costs a failed entry.
+	ErrHardModeViolated = errors.New("hard-mode violated")
+	// ErrGameOver is returned when guessing after the game has ended.
+	ErrGameOver = errors.New("game is over")
+	// ErrNotSolved is returned when continuing a game that was not won.
+	ErrNotSolved = errors.New("only a solved game can continue")
+	// ErrNoGuessesLeft is returned when continuing a game without any guesses to carry over.
+	ErrNoGuessesLeft = errors.New("no guesses left to continue with")
+)
+
+// Config holds everything needed to start a GameSession.
+type Config struct {
+	WordLen     int
+	NumGuesses  int // the guesses for a single board. see ScaledGuesses
+	MaxNumFails int
+	HardMode    bool
+	NumBoards   int // the number of targets each guess is played against. defaults to 1
+	NumHints    int
+	Absurd      bool // no target is chosen, every guess gets the least helpful feedback. always a single board
+
+	Alphabet []rune        // the upper-case letters words are spelled with. defaults to AllRunes
+	Folding  map[rune]rune // accented letters of the alphabet and the plain letter they are typed as. see Fold
+
+	Words   []string // the valid guesses for WordLen
+	Answers []string // the pool targets are picked from. defaults to Words
+	Targets []string // one per board. picked at random from Answers when empty. ignored when Absurd
+}
+
+// ScaledGuesses is the guess budget when playing several boards: one extra guess per extra board.
+func ScaledGuesses(numGuesses, numBoards int) int {
+	return numGuesses + max(numBoards, 1) - 1
+}
+
+// Result reports the outcome of a submitted guess.
+type Result struct {
+	Rows        [][]Cell // the scored guess on each board. nil when the guess was rejected or the board was already solved
+	State       GameState
+	GuessesLeft int
+	FailsLeft   int
+	HintsLeft   int
+}
+
+// GameSession is the headless game. It can be driven a rune at a time with
+// PushRune/PopRune/Submit or a word at a time with Guess.
+type GameSession struct {
+	config Config
+
+	WordLen     int
+	NumGuesses  int // already scaled by the number of boards
+	MaxNumFails int // counts down as failed entries are made
+	HintsLeft   int
+	HardMode    bool
+	Absurd      bool
+
+	Boards     []*Board
+	curIdx     int
+	guesses    []string
+	hints      []Hint
+	validWords []string
+	alphabet   []rune // the letters that can be typed, which leaves out the ones folded away
+
+	state GameState
+}
+
+func NewGameSession(cfg Config) (*GameSession, error) {
+	if cfg.WordLen < 1 || cfg.NumGuesses < 1 || cfg.MaxNumFails < 1 {
+		return nil, errors.New("word length, guesses and failed entries must all be positive")
+	}
+	if cfg.NumHints < 0 {
+		return nil, errors.New("hints can not be negative")
+	}
+	if cfg.NumBoards < 1 || cfg.Absurd {
+		cfg.NumBoards = 1
+	}
+	if len(cfg.Words) == 0 {
+		return nil, errors.New("no words to play with")
+	}
+	if len(cfg.Answers) == 0 {
+		cfg.Answers = cfg.Words
+	}
+	if len(cfg.Alphabet) == 0 {
+		cfg.Alphabet = AllRunes
+	}
+	gs := &GameSession{
+		config:      cfg,
+		WordLen:     cfg.WordLen,
+		NumGuesses:  ScaledGuesses(cfg.NumGuesses, cfg.NumBoards),
+		MaxNumFails: cfg.MaxNumFails,
+		HintsLeft:   cfg.NumHints,
+		HardMode:    cfg.HardMode,
+		Absurd:      cfg.Absurd,
+		curIdx:      0,
+		validWords:  cfg.Words,
+		state:       ACTIVE,
+	}
+	for _, r := range cfg.Alphabet {
+		if _, folded := cfg.Folding[r]; !folded {
+			gs.alphabet = append(gs.alphabet, r)
+		}
+	}
+
+	if gs.Absurd {
+		// the target could be any valid word, so the whole list stays in play
+		gs.Boards = []*Board{newAdversarialBoard(gs.NumGuesses, gs.validWords, gs.alphabet, cfg.Folding)}
+		return gs, nil
+	}
+
+	targets, err := gs.pickTargets(cfg.Targets)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		gs.Boards = append(gs.Boards, newBoard(gs.NumGuesses, target, gs.alphabet, cfg.Folding))
+	}
+
+	return gs, nil
+}
+
+// pickTargets checks the given targets, or picks distinct ones at random when there are none.
+func (gs *GameSession) pickTargets(targets []string) ([]string, error) {
+	numBoards := gs.config.NumBoards
+	if len(targets) == 0 {
+		if len(gs.config.Answers) < numBoards {
+			return nil, fmt.Errorf("not enough answers for %d boards", numBoards)
+		}
+		for _, idx := range rand.Perm(len(gs.config.Answers))[:numBoards] {
+			targets = append(targets, gs.config.Answers[idx])
+		}
+	}
+	if len(targets) != numBoards {
+		return nil, fmt.Errorf("expected %d targets, got %d", numBoards, len(targets))
+	}
+	for _, target := range targets {
+		if len([]rune(target)) != gs.WordLen {
+			return nil, errors.New("target does not match the word length")
+		}
+	}
+	return targets, nil
+}
+
+func (gs *GameSession) setState(state GameState) {
+	if !slices.Contains(gameStates, state) {
+		return
+	}
+	gs.state = state
+}
+
+func (gs *GameSession) GetState() GameState {
+	return gs.state
+}
+
+// Target is the upper-cased word of the first board, which is the only board unless
+// playing several. Frontends should only show it once the game is over.
+func (gs *GameSession) Target() string {
+	return gs.Boards[0].Target()
+}
+
+// Targets are the upper-cased words of every board.
+func (gs *GameSession) Targets() []string {
+	targets := []string{}
+	for _, b := range gs.Boards {
+		targets = append(targets, b.Target())
+	}
+	return targets
+}
+
+// GuessesUsed is the number of guesses that have been scored.
+func (gs *GameSession) GuessesUsed() int {
+	return gs.curIdx
+}
+
+// Guesses are the scored guesses so far, upper-cased.
+func (gs *GameSession) Guesses() []string {
+	return slices.Clone(gs.guesses)
+}
+
+// activeBoards are the boards that are still being played.
+func (gs *GameSession) activeBoards() []*Board {
+	active := []*Board{}
+	for _, b := range gs.Boards {
+		if !b.solved {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
+// Alphabet is the upper-case letters that can be typed. Letters that are folded are left out.
+func (gs *GameSession) Alphabet() []rune {
+	return slices.Clone(gs.alphabet)
+}
+
+// Folding is the plain letter each accented letter is typed as. nil when nothing is folded.
+func (gs *GameSession) Folding() map[rune]rune {
+	return maps.Clone(gs.config.Folding)
+}
+
+// InAlphabet reports whether r, in either case, is a letter of the alphabet once folded.
+func (gs *GameSession) InAlphabet(r rune) bool {
+	return slices.Contains(gs.alphabet, Fold(gs.config.Folding, unicode.ToUpper(r)))
+}
+
+// PushRune types r into the current guess. Runes outside of the alphabet are ignored and
+// accented letters are typed as the plain letter they fold to.
+func (gs *GameSession) PushRune(r rune) {
+	if gs.state != ACTIVE || !gs.InAlphabet(r) {
+		return
+	}
+	r = Fold(gs.config.Folding, unicode.ToUpper(r))
+	for _, b := range gs.activeBoards() {
+		if len(b.Grid[b.curIdx]) == gs.WordLen { // bounds checking
+			continue
+		}
+		cell := Cell{
+			Char:  r,
+			state: DEFAULT,
+		}
+		b.Grid[b.curIdx] = append(b.Grid[b.curIdx], cell)
+	}
+}
+
+func (gs *GameSession) PopRune() {
+	if gs.state != ACTIVE {
+		return
+	}
+	for _, b := range gs.activeBoards() {
+		if len(b.Grid[b.curIdx]) == 0 { // bounds checking
+			continue
+		}
+		b.Grid[b.curIdx] = b.Grid[b.curIdx][:len(b.Grid[b.curIdx])-1]
+	}
+}
+
+func (gs *GameSession) ClearCurrentGuess() {
+	if gs.state != ACTIVE {
+		return
+	}
+	for _, b := range gs.activeBoards() {
+		b.Grid[b.curIdx] = nil
+	}
+}
+
+// CurrentGuess is the upper-cased, unsubmitted guess.
+func (gs *GameSession) CurrentGuess() string {
+	active := gs.activeBoards()
+	if len(active) == 0 || active[0].curIdx == gs.NumGuesses {
+		return ""
+	}
+	return active[0].curGuessAsUpperString()
+}
+
+// GiveUp ends the game as a loss.
+func (gs *GameSession) GiveUp() {
+	gs.setState(LOSS)
+}
+
+// TimeOut ends a game that is still being played because its time ran out.
+func (gs *GameSession) TimeOut() {
+	if gs.state == ACTIVE {
+		gs.setState(TIMEOUT)
+	}
+}
+
+// Guess replaces the current row with word and submits it.
+func (gs *GameSession) Guess(word string) (Result, error) {
+	if gs.state != ACTIVE {
+		return gs.result(nil), ErrGameOver
+	}
+	if len([]rune(word)) != gs.WordLen {
+		return gs.result(nil), ErrWrongLength
+	}
+	gs.ClearCurrentGuess()
+	for _, r := range word {
+		gs.PushRune(r)
+	}
+	return gs.Submit()
+}
+
+// Submit scores the current row. Rejected guesses are left in place so they can be edited.
+func (gs *GameSession) Submit() (Result, error) {
+	if gs.state != ACTIVE {
+		return gs.result(nil), ErrGameOver
+	}
+	if len([]rune(gs.CurrentGuess())) != gs.WordLen {
+		return gs.result(nil), ErrWrongLength
+	}
+
+	if !gs.isValidWord() {
+		gs.failEntry()
+		return gs.result(nil), ErrInvalidWord
+	}
+
+	if gs.HardMode && !gs.isHardModeSatisfied() {
+		gs.failEntry()
+		return gs.result(nil), ErrHardModeViolated
+	}
+
+	guess := gs.CurrentGuess()
+	rows := make([][]Cell, len(gs.Boards))
+	for i, b := range gs.Boards {
+		if b.solved {
+			continue
+		}
+		rows[i] = b.Grid[b.curIdx]
+		b.finalizeCurRow()
+	}
+	gs.curIdx += 1
+	gs.guesses = append(gs.guesses, guess)
+	gs.updateState()
+
+	return gs.result(rows), nil
+}
+
+// updateState ends the game once every board is solved or the guesses have run out.
+func (gs *GameSession) updateState() {
+	if len(gs.activeBoards()) == 0 {
+		gs.setState(VICTORY)
+	} else if gs.curIdx == gs.NumGuesses {
+		gs.setState(LOSS)
+	}
+}
+
+func (gs *GameSession) failEntry() {
+	gs.MaxNumFails -= 1
+	if gs.MaxNumFails == 0 {
+		gs.setState(LOSS)
+	}
+}
+
+func (gs *GameSession) result(rows [][]Cell) Result {
+	res := Result{
+		State:       gs.state,
+		GuessesLeft: gs.NumGuesses - gs.curIdx,
+		FailsLeft:   gs.MaxNumFails,
+		HintsLeft:   gs.HintsLeft,
+	}
+	for _, row := range rows {
+		res.Rows = append(res.Rows, slices.Clone(row))
+	}
+	return res
+}
+
+func (gs *GameSession) curGuessAsLowerString() string {
+	return strings.ToLower(gs.CurrentGuess())
+}
+
+// isHardModeSatisfied checks the guess against the hints of every board still being played.
+func (gs *GameSession) isHardModeSatisfied() bool {
+	for _, b := range gs.activeBoards() {
+		if !b.isHardModeSatisfied() {
+			return false
+		}
+	}
+	return true
+}
+
+func (gs *GameSession) isValidWord() bool {
+	if gs.config.Folding == nil {
+		return slices.Contains(gs.validWords, gs.curGuessAsLowerString())
+	}
+	// the guess is already folded, so it is one of the words once they are folded too
+	guess := gs.CurrentGuess()
+	return slices.ContainsFunc(gs.validWords, func(word string) bool {
+		return string(FoldRunes(gs.config.Folding, []rune(strings.ToUpper(word)))) == guess
+	})
+}
+
+// GuessesLeft is the number of guesses that can still be made on the current word.
+func (gs *GameSession) GuessesLeft() int {
+	return gs.NumGuesses - gs.curIdx
+}
+
+// Continue moves a won game on to new targets, carrying over the guesses and failed entries
+// left. bonus guesses are added on top. Targets are picked at random when none are given.
+func (gs *GameSession) Continue(targets []string, bonus int) error {
+	if gs.state != VICTORY {
+		return ErrNotSolved
+	}
+	rows := gs.GuessesLeft() + bonus
+	if rows < 1 {
+		return ErrNoGuessesLeft
+	}
+	failsLeft := gs.MaxNumFails
+	hintsLeft := gs.HintsLeft
+	if err := gs.Reset(targets); err != nil {
+		return err
+	}
+	gs.resize(rows)
+	gs.MaxNumFails = failsLeft
+	gs.HintsLeft = hintsLeft
+	return nil
+}
+
+// resize changes the number of guesses of a game that has not been played yet.
+func (gs *GameSession) resize(rows int) {
+	gs.NumGuesses = rows
+	for _, b := range gs.Boards {
+		b.Grid = make([][]Cell, rows)
+	}
+}
+
+// Reset starts a new game with the same configuration. Targets are picked at random when none are given.
+func (gs *GameSession) Reset(targets []string) error {
+	if !gs.Absurd {
+		var err error
+		if targets, err = gs.pickTargets(targets); err != nil {
+			return err
+		}
+	}
+
+	gs.curIdx = 0
+	gs.guesses = nil
+	gs.hints = nil
+	gs.setState(ACTIVE)
+	gs.MaxNumFails = gs.config.MaxNumFails
+	gs.HintsLeft = gs.config.NumHints
+	gs.resize(ScaledGuesses(gs.config.NumGuesses, gs.config.NumBoards))
+	for i, b := range gs.Boards {
+		b.reset()
+		if gs.Absurd {
+			b.setCandidates(gs.validWords)
+		} else {
+			b.setTarget(targets[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,842 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+const (
+	wordTests string = "tests"
+	wordWrong string = "wrong"
+	wordVolts string = "volts"
+)
+
+// mockNewSession starts a session of cfg with what the tests share filled in: six guesses, five
+// failed words, a board for every target and words as long as the first one. Targets are
+// always allowed.
+func mockNewSession(t *testing.T, cfg Config) *GameSession {
+	t.Helper()
+	words := slices.Clone(cfg.Targets)
+	for _, word := range cfg.Words {
+		if !slices.Contains(words, word) {
+			words = append(words, word)
+		}
+	}
+	cfg.Words = words
+	if cfg.WordLen == 0 {
+		cfg.WordLen = len([]rune(words[0]))
+	}
+	if cfg.NumGuesses == 0 {
+		cfg.NumGuesses = 6
+	}
+	if cfg.MaxNumFails == 0 {
+		cfg.MaxNumFails = 5
+	}
+	cfg.NumBoards = max(cfg.NumBoards, len(cfg.Targets))
+	gs, err := NewGameSession(cfg)
+	if err != nil {
+		t.Fatalf("could not start the session. err=%v", err)
+	}
+	return gs
+}
+
+// mockNewGameSession targets word on a single board. others are only allowed
+func mockNewGameSession(t *testing.T, word string, others ...string) *GameSession {
+	t.Helper()
+	return mockNewSession(t, Config{Targets: []string{word}, Words: others})
+}
+
+func TestIsValidWord(t *testing.T) {
+	gs := mockNewGameSession(t, wordTests)
+	var convenience_word_string string
+	for _, r := range wordTests {
+		gs.PushRune(r)
+		convenience_word_string += utils.RuneToAlpha(r)
+	}
+
+	if !gs.isValidWord() {
+		t.Fatalf("word=%s was not found in validWords", convenience_word_string)
+	}
+}
+
+func TestIsWinner(t *testing.T) {
+	gs := mockNewGameSession(t, wordTests)
+	for _, r := range wordTests {
+		gs.PushRune(r)
+	}
+
+	if !gs.Boards[0].IsWinner() {
+		t.Fatal("winner was not detected")
+	}
+
+	for i := 0; i < len(wordTests); i++ {
+		gs.PopRune()
+	}
+
+	for _, r := range wordWrong {
+		gs.PushRune(r)
+	}
+	if gs.Boards[0].IsWinner() {
+		t.Fatal("winner was incorrectly detected")
+	}
+}
+
+func TestFinalizeCurRow(t *testing.T) {
+	// WARN: these tests do not use the word constants because the output is also
+	// dependant the guess.
+	testCases := []struct {
+		name  string
+		word  string
+		guess string
+		row   []Cell
+	}{
+		{
+			"sanityCheck",
+			"volts",
+			"volts",
+			[]Cell{{'V', CORRECT}, {'O', CORRECT}, {'L', CORRECT}, {'T', CORRECT}, {'S', CORRECT}},
+		},
+		{
+			"twoInputOneOutputPreceeding",
+			"volts",
+			"lusts",
+			[]Cell{{'L', PARTIAL}, {'U', USED}, {'S', USED}, {'T', CORRECT}, {'S', CORRECT}},
+		},
+		{
+			"twoInputOneOutputFollowing",
+			"stims",
+			"sassy",
+			[]Cell{{'S', CORRECT}, {'A', USED}, {'S', PARTIAL}, {'S', USED}, {'Y', USED}},
+		},
+		{
+			"twoInputOneOutputFollowingWithOnePartial",
+			"stims",
+			"sissy",
+			[]Cell{{'S', CORRECT}, {'I', PARTIAL}, {'S', PARTIAL}, {'S', USED}, {'Y', USED}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := mockNewGameSession(t, tc.word)
+			for _, r := range tc.guess {
+				gs.PushRune(r)
+			}
+			b := gs.Boards[0]
+			b.finalizeCurRow()
+			b.curIdx -= 1
+			curRow := b.Grid[b.curIdx]
+			for i := range curRow {
+				if !curRow[i].isEqualTo(tc.row[i]) {
+					t.Fatalf(
+						"unexpected cell value at %d, got=%v. expected=%v. word=%s, guess=%s",
+						i,
+						curRow[i],
+						tc.row[i],
+						tc.word,
+						tc.guess,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestIsHardModeSatisfied(t *testing.T) {
+	testCases := []struct {
+		name        string
+		word        string
+		firstGuess  string
+		secondGuess string
+		expected    bool
+	}{
+		{
+			name:        "sanity check",
+			word:        "tests",
+			firstGuess:  "toast",
+			secondGuess: "toast",
+			expected:    true,
+		},
+		{
+			name:        "missing correct char",
+			word:        "tests",
+			firstGuess:  "toast",
+			secondGuess: "strap",
+			expected:    false,
+		},
+		{
+			name:        "two missing correct char",
+			word:        "tests",
+			firstGuess:  "tales",
+			secondGuess: "strap",
+			expected:    false,
+		},
+		{
+			name:        "missing partial, no correct",
+			word:        "tests",
+			firstGuess:  "adieu",
+			secondGuess: "short",
+			expected:    false,
+		},
+		{
+			name:        "two missing partial, no correct",
+			word:        "tests",
+			firstGuess:  "stick",
+			secondGuess: "pound",
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := mockNewGameSession(t, tc.word)
+			for _, r := range tc.firstGuess {
+				gs.PushRune(r)
+			}
+			b := gs.Boards[0]
+			b.finalizeCurRow()
+			for _, r := range tc.secondGuess {
+				gs.PushRune(r)
+			}
+			if b.isHardModeSatisfied() != tc.expected {
+				t.Fatalf("HardMode validation failure.\nword=%s\nfirst=%s\nsecond=%s\nexpected=%v\ngot=%v",
+					tc.word,
+					tc.firstGuess,
+					tc.secondGuess,
+					tc.expected,
+					b.isHardModeSatisfied(),
+				)
+			}
+		})
+	}
+}
+
+func TestCountByRune(t *testing.T) {
+	gs := mockNewGameSession(t, wordTests)
+	b := gs.Boards[0]
+	for _, r := range b.targetWordAsRunes {
+		gs.PushRune(r)
+	}
+	// WARN: this is done manually. if wordTests changes, this will also need to change
+	targetMap := map[rune]int{
+		'T': 2,
+		'E': 1,
+		'S': 2,
+	}
+	recievedMap := b.countMapForTargetWord()
+	for k := range targetMap {
+		if targetMap[k] != recievedMap[k] {
+			t.Fatalf("unexpected count map for %s:\n%v\nexpected:\n%v", b.targetWordAsString, recievedMap, targetMap)
+		}
+	}
+	for k := range recievedMap {
+		if targetMap[k] != recievedMap[k] {
+			t.Fatalf("unexpected count map for %s:\n%v\nexpected:\n%v", b.targetWordAsString, recievedMap, targetMap)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		target   string
+		guess    string
+		expected []CellState
+	}{
+		{"allCorrect", "VOLTS", "VOLTS", []CellState{CORRECT, CORRECT, CORRECT, CORRECT, CORRECT}},
+		{"noneUsed", "VOLTS", "CHAIR", []CellState{USED, USED, USED, USED, USED}},
+		{"repeatedGuessLetter", "VOLTS", "LUSTS", []CellState{PARTIAL, USED, USED, CORRECT, CORRECT}},
+		{"repeatedBothWays", "STIMS", "SISSY", []CellState{CORRECT, PARTIAL, PARTIAL, USED, USED}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Score([]rune(tc.guess), []rune(tc.target))
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("unexpected score. got=%v, expected=%v", got, tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestGuess(t *testing.T) {
+	testCases := []struct {
+		name      string
+		hardMode  bool
+		guesses   []string
+		lastErr   error
+		state     GameState
+		failsLeft int
+	}{
+		{"victory", false, []string{"toast", "tests"}, nil, VICTORY, 5},
+		{"wrongLengthIsFree", false, []string{"test"}, ErrWrongLength, ACTIVE, 5},
+		{"invalidWordCostsAFail", false, []string{"zzzzz"}, ErrInvalidWord, ACTIVE, 4},
+		{"hardModeCostsAFail", true, []string{"toast", "adieu"}, ErrHardModeViolated, ACTIVE, 4},
+		{"hardModeIgnoredWhenOff", false, []string{"toast", "adieu"}, nil, ACTIVE, 5},
+		{"outOfFails", false, []string{"zzzzz", "zzzzz", "zzzzz", "zzzzz", "zzzzz"}, ErrInvalidWord, LOSS, 0},
+		{"outOfGuesses", false, []string{"toast", "toast", "toast", "toast", "toast", "toast"}, nil, LOSS, 5},
+		{"victoryOnLastGuess", false, []string{"toast", "toast", "toast", "toast", "toast", "tests"}, nil, VICTORY, 5},
+		{"gameOver", false, []string{"tests", "toast"}, ErrGameOver, VICTORY, 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := mockNewGameSession(t, wordTests, "toast", "adieu")
+			gs.HardMode = tc.hardMode
+			var res Result
+			var err error
+			for _, guess := range tc.guesses {
+				res, err = gs.Guess(guess)
+			}
+			if !errors.Is(err, tc.lastErr) {
+				t.Fatalf("unexpected error. got=%v, expected=%v", err, tc.lastErr)
+			}
+			if res.State != tc.state || gs.GetState() != tc.state {
+				t.Fatalf("unexpected state. got=%v, expected=%v", res.State, tc.state)
+			}
+			if res.FailsLeft != tc.failsLeft {
+				t.Fatalf("unexpected fails left. got=%d, expected=%d", res.FailsLeft, tc.failsLeft)
+			}
+		})
+	}
+}
+
+func TestSeenCharsKeepBestState(t *testing.T) {
+	gs := mockNewGameSession(t, "volts", "lusts", "sloth")
+	gs.Guess("volts")
+	gs.Reset([]string{"volts"})
+	gs.Guess("lusts")
+	gs.Guess("sloth")
+
+	expected := map[rune]CellState{'L': PARTIAL, 'U': USED, 'S': CORRECT, 'T': CORRECT, 'O': PARTIAL, 'H': USED}
+	for _, cell := range gs.Boards[0].SeenChars {
+		if state, ok := expected[cell.Char]; ok && cell.GetState() != state {
+			t.Fatalf("unexpected seen state for %c. got=%v, expected=%v", cell.Char, cell.GetState(), state)
+		}
+	}
+}
+
+func TestDailyTarget(t *testing.T) {
+	words := []string{"tests", "toast", "adieu", "volts", "lusts", "sloth", "stims", "sissy"}
+	reversed := slices.Clone(words)
+	slices.Reverse(reversed)
+	day := time.Date(2024, time.January, 2, 8, 0, 0, 0, time.UTC)
+
+	target := DailyTarget(words, 5, day)
+	if !slices.Contains(words, target) {
+		t.Fatalf("target=%s is not in the word list", target)
+	}
+	if DailyTarget(reversed, 5, day) != target {
+		t.Fatal("daily target depends on the order of the word list")
+	}
+	if DailyTarget(words, 5, day.Add(12*time.Hour)) != target {
+		t.Fatal("daily target changed within the same day")
+	}
+	if DailySeed(day, 5) == DailySeed(day.AddDate(0, 0, 1), 5) || DailySeed(day, 5) == DailySeed(day, 6) {
+		t.Fatal("daily seed does not depend on the date and word length")
+	}
+}
+
+func TestDailySeedUTC(t *testing.T) {
+	midnight := time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC)
+	newYork := time.FixedZone("EST", -5*60*60)
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	testCases := []struct {
+		name     string
+		date     time.Time
+		expected time.Time
+	}{
+		{"lastSecond", midnight.Add(-time.Second), midnight.AddDate(0, 0, -1)},
+		{"midnight", midnight, midnight},
+		{"behindUTC", midnight.In(newYork), midnight},                                    // still January 2 in New York
+		{"aheadOfUTC", midnight.Add(-time.Second).In(tokyo), midnight.AddDate(0, 0, -1)}, // already January 3 in Tokyo
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, expected := DailySeed(tc.date, 5), DailySeed(tc.expected, 5); got != expected {
+				t.Fatalf("unexpected seed for %v. got=%v, expected=%v", tc.date, got, expected)
+			}
+		})
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	gs := mockNewGameSession(t, wordTests, "toast", "adieu")
+	gs.Guess("toast")
+	gs.Guess("zzzzz")
+	gs.ClearCurrentGuess()
+	gs.Guess("adieu")
+	gs.PushRune('t')
+	gs.PushRune('e')
+
+	restored, err := Restore(gs.Snapshot(), gs.config.Answers, gs.validWords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Target() != gs.Target() || restored.MaxNumFails != 4 || restored.CurrentGuess() != "TE" {
+		t.Fatalf("restored game does not match. target=%s, fails=%d, input=%s",
+			restored.Target(), restored.MaxNumFails, restored.CurrentGuess())
+	}
+	for i, b := range gs.Boards[0].Grid {
+		for j := range b {
+			if !restored.Boards[0].Grid[i][j].isEqualTo(b[j]) {
+				t.Fatalf("restored grid does not match at %d,%d", i, j)
+			}
+		}
+	}
+	for i, seen := range gs.Boards[0].SeenChars {
+		if !restored.Boards[0].SeenChars[i].isEqualTo(seen) {
+			t.Fatalf("restored seen chars do not match at %c", seen.Char)
+		}
+	}
+
+	if _, err := restored.Guess("tests"); err != nil || restored.GetState() != VICTORY {
+		t.Fatalf("restored game could not be finished. err=%v", err)
+	}
+}
+
+func TestTargetsAreAnswers(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		gs, err := NewGameSession(Config{
+			WordLen:     5,
+			NumGuesses:  6,
+			MaxNumFails: 5,
+			Words:       []string{"tests", "toast", "adieu"},
+			Answers:     []string{"tests"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gs.Target() != "TESTS" {
+			t.Fatalf("target=%s was not drawn from the answers", gs.Target())
+		}
+		if _, err := gs.Guess("toast"); err != nil {
+			t.Fatalf("an allowed word was rejected. err=%v", err)
+		}
+	}
+}
+
+func TestMultiBoard(t *testing.T) {
+	gs := mockNewSession(t, Config{Targets: []string{"tests", "volts"}, Words: []string{"toast"}})
+	if gs.NumGuesses != 7 || len(gs.Boards) != 2 {
+		t.Fatalf("unexpected setup. guesses=%d, boards=%d", gs.NumGuesses, len(gs.Boards))
+	}
+
+	res, err := gs.Guess("volts")
+	if err != nil || res.State != ACTIVE {
+		t.Fatalf("unexpected result. state=%v, err=%v", res.State, err)
+	}
+	if !gs.Boards[1].IsSolved() || gs.Boards[0].IsSolved() {
+		t.Fatal("only the second board should be solved")
+	}
+	if len(res.Rows) != 2 || res.Rows[1][0].GetState() != CORRECT {
+		t.Fatalf("unexpected rows. got=%v", res.Rows)
+	}
+
+	// the solved board is locked
+	res, _ = gs.Guess("toast")
+	if res.Rows[1] != nil || gs.Boards[1].RowsUsed() != 1 || gs.Boards[0].RowsUsed() != 2 {
+		t.Fatal("a solved board kept being played")
+	}
+
+	res, _ = gs.Guess("tests")
+	if res.State != VICTORY {
+		t.Fatalf("solving every board was not a victory. state=%v", res.State)
+	}
+	if !slices.Equal(gs.Guesses(), []string{"VOLTS", "TOAST", "TESTS"}) {
+		t.Fatalf("unexpected guesses. got=%v", gs.Guesses())
+	}
+}
+
+func TestMultiBoardHardMode(t *testing.T) {
+	gs := mockNewSession(t, Config{Targets: []string{"tests", "volts"}, Words: []string{"toast", "adieu"}})
+	gs.HardMode = true
+	gs.Guess("toast")
+
+	// fine for the first board, but volts's L and V are missing
+	if _, err := gs.Guess("tests"); !errors.Is(err, ErrHardModeViolated) {
+		t.Fatalf("hard-mode was not checked on every board. err=%v", err)
+	}
+}
+
+func TestSeededTargets(t *testing.T) {
+	words := []string{"tests", "toast", "adieu", "volts"}
+	targets, err := SeededTargets(words, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(targets)
+	if !slices.Equal(slices.Compact(targets), []string{"adieu", "tests", "toast", "volts"}) {
+		t.Fatalf("targets are not distinct. got=%v", targets)
+	}
+	if _, err := SeededTargets(words, 3, 5); err == nil {
+		t.Fatal("more boards than answers was accepted")
+	}
+}
+
+func TestSnapshotRestoreMultiBoard(t *testing.T) {
+	gs := mockNewSession(t, Config{Targets: []string{"tests", "volts"}, Words: []string{"toast"}})
+	gs.Guess("volts")
+	gs.Guess("toast")
+
+	restored, err := Restore(gs.Snapshot(), gs.config.Answers, gs.validWords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Boards[1].IsSolved() || restored.Boards[1].RowsUsed() != 1 || restored.GuessesUsed() != 2 {
+		t.Fatal("restored boards do not match")
+	}
+}
+
+func TestAbsurd(t *testing.T) {
+	gs := mockNewSession(t, Config{Absurd: true, Words: []string{"aaa", "aab", "abb", "bbb", "ccc"}})
+
+	// ccc splits the words into itself and the four without a c
+	if _, err := gs.Guess("ccc"); err != nil || gs.GetState() != ACTIVE {
+		t.Fatalf("the adversary gave up a word. err=%v, state=%v", err, gs.GetState())
+	}
+	if got := gs.Boards[0].Candidates(); !slices.Equal(got, []string{"AAA", "AAB", "ABB", "BBB"}) {
+		t.Fatalf("unexpected candidates. got=%v", got)
+	}
+
+	// every candidate is alone now, so the one revealing the least is kept
+	res, err := gs.Guess("aaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cell := range res.Rows[0] {
+		if cell.GetState() != USED {
+			t.Fatalf("the adversary revealed a letter. row=%v", res.Rows[0])
+		}
+	}
+	if gs.Target() != "BBB" {
+		t.Fatalf("unexpected target. got=%s, expected=BBB", gs.Target())
+	}
+
+	if _, err := gs.Guess("bbb"); err != nil || gs.GetState() != VICTORY {
+		t.Fatalf("the last word left did not win. err=%v, state=%v", err, gs.GetState())
+	}
+}
+
+func TestAbsurdHardMode(t *testing.T) {
+	gs := mockNewSession(t, Config{Absurd: true, Words: []string{"abc", "abd", "abe", "xyz"}})
+	gs.HardMode = true
+
+	// abd and abe share the biggest bucket, which reveals a and b as correct
+	gs.Guess("abc")
+	if _, err := gs.Guess("xyz"); !errors.Is(err, ErrHardModeViolated) {
+		t.Fatalf("ignoring the revealed letters was allowed. err=%v", err)
+	}
+}
+
+func TestSnapshotRestoreAbsurd(t *testing.T) {
+	gs := mockNewSession(t, Config{Absurd: true, Words: []string{"aaa", "aab", "abb", "bbb", "ccc"}})
+	gs.Guess("ccc")
+	gs.Guess("aaa")
+
+	restored, err := Restore(gs.Snapshot(), gs.config.Answers, gs.validWords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(restored.Boards[0].Candidates(), gs.Boards[0].Candidates()) || restored.Target() != gs.Target() {
+		t.Fatalf("restored candidates do not match. got=%v, expected=%v",
+			restored.Boards[0].Candidates(), gs.Boards[0].Candidates())
+	}
+
+	if err := restored.Reset(nil); err != nil || len(restored.Boards[0].Candidates()) != 5 {
+		t.Fatalf("reset did not bring every word back. err=%v", err)
+	}
+}
+
+// the allowed words of the hint tests, which target tests
+var hintWords = []string{"toast", "adieu", wordVolts, "lusts"}
+
+func TestHint(t *testing.T) {
+	gs := mockNewSession(t, Config{NumHints: 3, Targets: []string{wordTests}, Words: hintWords})
+
+	if hint, err := gs.Hint(HINT_POSITION); err != nil || hint.Pos != 0 || hint.Letter != 'T' {
+		t.Fatalf("unexpected hint. got=%+v, err=%v", hint, err)
+	}
+	// T is already known, so the next position is revealed
+	gs.Guess("toast")
+	if hint, err := gs.Hint(HINT_POSITION); err != nil || hint.Pos != 1 || hint.Letter != 'E' || hint.Turn != 1 {
+		t.Fatalf("unexpected hint. got=%+v, err=%v", hint, err)
+	}
+	if seen := gs.Boards[0].SeenChars[slices.Index(AllRunes, 'E')]; seen.GetState() != CORRECT {
+		t.Fatalf("the revealed letter was not marked as seen. got=%v", seen.GetState())
+	}
+
+	// every letter of TESTS has been seen
+	if _, err := gs.Hint(HINT_LETTER); !errors.Is(err, ErrNothingToReveal) || gs.HintsLeft != 1 {
+		t.Fatalf("a hint was spent on nothing. err=%v, left=%d", err, gs.HintsLeft)
+	}
+	if hint, err := gs.Hint(HINT_COUNT); err != nil || hint.Count != 1 {
+		t.Fatalf("unexpected count. got=%+v, err=%v", hint, err)
+	}
+	if _, err := gs.Hint(HINT_COUNT); !errors.Is(err, ErrNoHints) {
+		t.Fatalf("the hint budget was overspent. err=%v", err)
+	}
+	if gs.HintsUsed() != 3 || len(gs.Hints()) != 3 {
+		t.Fatalf("unexpected hints used. got=%d", gs.HintsUsed())
+	}
+}
+
+func TestHintLetter(t *testing.T) {
+	gs := mockNewSession(t, Config{NumHints: 2, Targets: []string{wordTests}, Words: hintWords})
+	gs.Guess("volts")
+
+	// T and S were seen, E was not
+	if hint, err := gs.Hint(HINT_LETTER); err != nil || hint.Letter != 'E' {
+		t.Fatalf("unexpected hint. got=%+v, err=%v", hint, err)
+	}
+	if hint, _ := gs.Hint(HINT_COUNT); hint.Count != 1 {
+		t.Fatalf("unexpected count. got=%d, expected=1", hint.Count)
+	}
+}
+
+func TestHintHardMode(t *testing.T) {
+	gs := mockNewSession(t, Config{NumHints: 1, HardMode: true, Targets: []string{wordTests}, Words: hintWords})
+	gs.Hint(HINT_POSITION)
+
+	if _, err := gs.Guess("adieu"); !errors.Is(err, ErrHardModeViolated) {
+		t.Fatalf("the revealed position was ignored. err=%v", err)
+	}
+	if _, err := gs.Guess("toast"); err != nil {
+		t.Fatalf("a guess using the revealed position was rejected. err=%v", err)
+	}
+}
+
+func TestSnapshotRestoreHints(t *testing.T) {
+	gs := mockNewSession(t, Config{NumHints: 3, Targets: []string{wordTests}, Words: hintWords})
+	gs.Hint(HINT_POSITION)
+	gs.Guess("toast")
+	gs.Hint(HINT_POSITION)
+
+	restored, err := Restore(gs.Snapshot(), gs.config.Answers, gs.validWords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.HintsLeft != 1 || !slices.Equal(restored.Hints(), gs.Hints()) {
+		t.Fatalf("restored hints do not match. got=%v, expected=%v", restored.Hints(), gs.Hints())
+	}
+
+	if err := restored.Reset(nil); err != nil || restored.HintsLeft != 3 || len(restored.Hints()) != 0 {
+		t.Fatalf("reset did not restore the hint budget. err=%v", err)
+	}
+}
+
+func TestSnapshotRestoreHintCount(t *testing.T) {
+	gs := mockNewSession(t, Config{NumHints: 1, Targets: []string{wordTests}, Words: hintWords, Answers: []string{wordTests, "toast", "lusts"}})
+	if hint, err := gs.Hint(HINT_COUNT); err != nil || hint.Count != 3 {
+		t.Fatalf("unexpected count. got=%d, expected=%d", hint.Count, 3)
+	}
+
+	restored, err := Restore(gs.Snapshot(), gs.config.Answers, gs.validWords)
+	if err != nil || !slices.Equal(restored.Hints(), gs.Hints()) {
+		t.Fatalf("restored count does not match. got=%v, expected=%v, err=%v", restored.Hints(), gs.Hints(), err)
+	}
+	// counted against every allowed word, the count would not be the same
+	if _, err := Restore(gs.Snapshot(), nil, gs.validWords); err == nil {
+		t.Fatal("a count of a different answer pool was restored")
+	}
+}
+
+func TestTimeOut(t *testing.T) {
+	gs := mockNewGameSession(t, wordTests)
+	gs.Guess(wordTests)
+	if gs.TimeOut(); gs.GetState() != VICTORY {
+		t.Fatalf("a finished game timed out. state=%v", gs.GetState())
+	}
+
+	gs.Reset(nil)
+	gs.TimeOut()
+	if _, err := gs.Guess(wordTests); !errors.Is(err, ErrGameOver) || gs.GetState() != TIMEOUT {
+		t.Fatalf("a timed out game could still be played. err=%v, state=%v", err, gs.GetState())
+	}
+}
+
+func TestContinue(t *testing.T) {
+	gs := mockNewGameSession(t, wordTests, "toast", wordVolts)
+	if err := gs.Continue(nil, 0); !errors.Is(err, ErrNotSolved) {
+		t.Fatalf("an unsolved game continued. err=%v", err)
+	}
+
+	gs.Guess("zzzzz")
+	gs.Guess("toast")
+	gs.Guess(wordTests)
+	if err := gs.Continue([]string{wordVolts}, 2); err != nil {
+		t.Fatal(err)
+	}
+	// 6 guesses - 2 used + 2 bonus, and the failed entry stays spent
+	if gs.GetState() != ACTIVE || gs.NumGuesses != 6 || len(gs.Boards[0].Grid) != 6 || gs.MaxNumFails != 4 {
+		t.Fatalf("unexpected game. state=%v, guesses=%d, fails=%d", gs.GetState(), gs.NumGuesses, gs.MaxNumFails)
+	}
+	if gs.Target() != "VOLTS" || gs.GuessesUsed() != 0 {
+		t.Fatalf("the game did not move on. target=%s", gs.Target())
+	}
+
+	gs.Guess("toast")
+	restored, err := Restore(gs.Snapshot(), gs.config.Answers, gs.validWords)
+	if err != nil || restored.GuessesLeft() != 5 || restored.MaxNumFails != 4 {
+		t.Fatalf("the carried over guesses were not restored. err=%v", err)
+	}
+
+	if gs.Reset(nil); gs.NumGuesses != 6 || gs.MaxNumFails != 5 {
+		t.Fatalf("reset did not start over. guesses=%d, fails=%d", gs.NumGuesses, gs.MaxNumFails)
+	}
+}
+
+func TestContinueWithoutGuesses(t *testing.T) {
+	gs := mockNewGameSession(t, wordTests, "toast")
+	for i := 0; i < 5; i++ {
+		gs.Guess("toast")
+	}
+	gs.Guess(wordTests)
+	if err := gs.Continue(nil, 0); !errors.Is(err, ErrNoGuessesLeft) {
+		t.Fatalf("continued without any guesses. err=%v", err)
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	gs, err := NewGameSession(Config{
+		WordLen:     4,
+		NumGuesses:  6,
+		MaxNumFails: 5,
+		Alphabet:    []rune("ABCDEFGHIJKLMNÑOPQRSTUVWXYZ"),
+		Words:       []string{"niño", "nido"},
+		Targets:     []string{"niño"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range "niéño" {
+		gs.PushRune(r)
+	}
+	if gs.CurrentGuess() != "NIÑO" {
+		t.Fatalf("letters outside of the alphabet were typed. got=%q, expected=%q", gs.CurrentGuess(), "NIÑO")
+	}
+	gs.ClearCurrentGuess()
+	if _, err := gs.Guess("nido"); err != nil {
+		t.Fatal(err)
+	}
+	if seen := gs.Boards[0].SeenChars[slices.Index(gs.Alphabet(), 'D')]; seen.GetState() != USED {
+		t.Fatalf("unexpected seen state for D. got=%v, expected=%v", seen.GetState(), USED)
+	}
+	if _, err := gs.Guess("niño"); err != nil || gs.GetState() != VICTORY {
+		t.Fatalf("the word with Ñ could not be guessed. err=%v", err)
+	}
+	if seen := gs.Boards[0].SeenChars[slices.Index(gs.Alphabet(), 'Ñ')]; seen.GetState() != CORRECT {
+		t.Fatalf("unexpected seen state for Ñ. got=%v, expected=%v", seen.GetState(), CORRECT)
+	}
+
+	english := mockNewGameSession(t, wordTests)
+	english.PushRune('é')
+	if english.CurrentGuess() != "" {
+		t.Fatalf("a letter outside of the default alphabet was typed. got=%q", english.CurrentGuess())
+	}
+}
+
+// foldingConfig plays Spanish words whose accented vowels fold to plain ones
+var foldingConfig = Config{
+	Alphabet: []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZÁÉÍÓÚ"),
+	Folding:  map[rune]rune{'Á': 'A', 'É': 'E', 'Í': 'I', 'Ó': 'O', 'Ú': 'U'},
+	Words:    []string{"melón", "salón", "lápiz"},
+	Targets:  []string{"limón"},
+}
+
+func TestFolding(t *testing.T) {
+	gs := mockNewSession(t, foldingConfig)
+	for _, r := range "melón" {
+		gs.PushRune(r)
+	}
+	if gs.CurrentGuess() != "MELON" {
+		t.Fatalf("accented letters were not folded. got=%q, expected=%q", gs.CurrentGuess(), "MELON")
+	}
+	res, err := gs.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []CellState{PARTIAL, USED, PARTIAL, CORRECT, CORRECT}
+	for i, cell := range res.Rows[0] {
+		if cell.GetState() != expected[i] {
+			t.Fatalf("wrong state at %d. got=%v, expected=%v", i, cell.GetState(), expected[i])
+		}
+	}
+	if got := rowString(res.Rows[0]); got != "MELÓN" {
+		t.Fatalf("the scored row does not show the letters of the target. got=%q, expected=%q", got, "MELÓN")
+	}
+	if slices.Contains(gs.Alphabet(), 'Ó') {
+		t.Fatalf("a folded letter can be typed. got=%q", string(gs.Alphabet()))
+	}
+	if seen := gs.Boards[0].SeenChars[slices.Index(gs.Alphabet(), 'O')]; seen.GetState() != CORRECT {
+		t.Fatalf("unexpected seen state for O. got=%v, expected=%v", seen.GetState(), CORRECT)
+	}
+
+	if _, err := gs.Guess("limon"); err != nil || gs.GetState() != VICTORY {
+		t.Fatalf("the plain spelling did not win. err=%v", err)
+	}
+	if got := rowString(gs.Boards[0].Grid[1]); got != "LIMÓN" {
+		t.Fatalf("unexpected winning row. got=%q, expected=%q", got, "LIMÓN")
+	}
+
+	unfolded, _ := NewGameSession(Config{
+		WordLen:     5,
+		NumGuesses:  6,
+		MaxNumFails: 5,
+		Alphabet:    []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZÁÉÍÓÚ"),
+		Words:       []string{"limón", "melón"},
+		Targets:     []string{"limón"},
+	})
+	if _, err := unfolded.Guess("limon"); err != ErrInvalidWord {
+		t.Fatalf("the plain spelling was accepted without folding. got=%v, expected=%v", err, ErrInvalidWord)
+	}
+	if _, err := unfolded.Guess("limón"); err != nil || unfolded.GetState() != VICTORY {
+		t.Fatalf("the accented spelling did not win. err=%v", err)
+	}
+}
+
+func TestFoldingHardMode(t *testing.T) {
+	cfg := foldingConfig
+	cfg.HardMode = true
+	gs := mockNewSession(t, cfg)
+	if _, err := gs.Guess("melon"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gs.Guess("salon"); err != ErrHardModeViolated {
+		t.Fatalf("a missing PARTIAL was allowed. got=%v, expected=%v", err, ErrHardModeViolated)
+	}
+	if _, err := gs.Guess("limon"); err != nil {
+		t.Fatalf("a plain guess matching the accented CORRECT was rejected. err=%v", err)
+	}
+}
+
+func TestSnapshotRestoreFolding(t *testing.T) {
+	gs := mockNewSession(t, foldingConfig)
+	gs.Guess("melon")
+	restored, err := Restore(gs.Snapshot(), nil, []string{"limón", "melón", "salón", "lápiz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rowString(restored.Boards[0].Grid[0]); got != "MELÓN" {
+		t.Fatalf("unexpected restored row. got=%q, expected=%q", got, "MELÓN")
+	}
+	if _, err := restored.Guess("limon"); err != nil || restored.GetState() != VICTORY {
+		t.Fatalf("the folding was not restored. err=%v", err)
+	}
+}
+
+func rowString(row []Cell) string {
+	word := ""
+	for _, cell := range row {
+		word += string(cell.Char)
+	}
+	return word
+}
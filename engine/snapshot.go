@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Snapshot is the serializable state of a game. The grid and seen characters are not
+// stored since replaying the guesses against the target rebuilds them exactly. Absurd
+// games are rebuilt the same way since the candidates are always narrowed alike.
+type Snapshot struct {
+	WordLen     int               `json:"word_len"`
+	NumGuesses  int               `json:"num_guesses"`
+	Rows        int               `json:"rows,omitempty"` // the guesses of the current word when they were carried over by Continue
+	MaxNumFails int               `json:"max_num_fails"`
+	HardMode    bool              `json:"hard_mode"`
+	NumBoards   int               `json:"num_boards"`
+	NumHints    int               `json:"num_hints,omitempty"`
+	HintsLeft   int               `json:"hints_left,omitempty"`
+	Absurd      bool              `json:"absurd,omitempty"`
+	Alphabet    string            `json:"alphabet,omitempty"`
+	Folding     map[string]string `json:"folding,omitempty"`
+	FailsLeft   int               `json:"fails_left"`
+	Targets     []string          `json:"targets"`
+	Guesses     []string          `json:"guesses"`
+	Input       string            `json:"input"` // the unsubmitted guess
+	Hints       []Hint            `json:"hints,omitempty"`
+}
+
+func (gs *GameSession) Snapshot() Snapshot {
+	return Snapshot{
+		WordLen:     gs.WordLen,
+		NumGuesses:  gs.config.NumGuesses,
+		Rows:        gs.NumGuesses,
+		MaxNumFails: gs.config.MaxNumFails,
+		HardMode:    gs.HardMode,
+		NumBoards:   len(gs.Boards),
+		NumHints:    gs.config.NumHints,
+		HintsLeft:   gs.HintsLeft,
+		Absurd:      gs.Absurd,
+		Alphabet:    string(gs.config.Alphabet),
+		Folding:     foldingToStrings(gs.config.Folding),
+		FailsLeft:   gs.MaxNumFails,
+		Targets:     gs.Targets(),
+		Guesses:     gs.Guesses(),
+		Input:       gs.CurrentGuess(),
+		Hints:       gs.Hints(),
+	}
+}
+
+// Restore rebuilds a game from a snapshot. answers and words are the answer pool and the
+// valid guesses for its word length.
+func Restore(snap Snapshot, answers, words []string) (*GameSession, error) {
+	if len(snap.Targets) == 0 && !snap.Absurd {
+		return nil, errors.New("snapshot has no targets")
+	}
+	gs, err := NewGameSession(Config{
+		WordLen:     snap.WordLen,
+		NumGuesses:  snap.NumGuesses,
+		MaxNumFails: snap.MaxNumFails,
+		HardMode:    snap.HardMode,
+		NumBoards:   snap.NumBoards,
+		NumHints:    snap.NumHints,
+		Absurd:      snap.Absurd,
+		Alphabet:    []rune(snap.Alphabet),
+		Folding:     foldingFromStrings(snap.Folding),
+		Words:       words,
+		Answers:     answers,
+		Targets:     snap.Targets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if snap.Rows > 0 {
+		gs.resize(snap.Rows)
+	}
+	if len(snap.Guesses) > gs.NumGuesses {
+		return nil, errors.New("snapshot has more guesses than allowed")
+	}
+	if snap.FailsLeft < 0 || snap.FailsLeft > snap.MaxNumFails {
+		return nil, errors.New("snapshot has an impossible number of failed entries")
+	}
+
+	// hints are taken again at the turn they were first taken, which reveals the same thing
+	hints := snap.Hints
+	replayHints := func() error {
+		for len(hints) > 0 && hints[0].Turn == gs.curIdx {
+			hint, err := gs.Hint(hints[0].Kind)
+			if err != nil || hint != hints[0] {
+				return errors.New("snapshot hints do not match the game")
+			}
+			hints = hints[1:]
+		}
+		return nil
+	}
+
+	// the guesses were validated when they were made, so they are scored directly
+	for _, guess := range snap.Guesses {
+		if err := replayHints(); err != nil {
+			return nil, err
+		}
+		if len([]rune(guess)) != gs.WordLen {
+			return nil, fmt.Errorf("snapshot guess %q does not match the word length", guess)
+		}
+		for _, r := range guess {
+			gs.PushRune(r)
+		}
+		for _, b := range gs.activeBoards() {
+			b.finalizeCurRow()
+		}
+		gs.curIdx += 1
+		gs.guesses = append(gs.guesses, strings.ToUpper(guess))
+		gs.updateState()
+	}
+	if err := replayHints(); err != nil {
+		return nil, err
+	}
+	if len(hints) > 0 {
+		return nil, errors.New("snapshot hints do not match the game")
+	}
+	// snapshots from before Continue existed have no rows, and their hints left come from the replay
+	if snap.Rows > 0 {
+		if snap.HintsLeft < 0 || snap.HintsLeft > gs.HintsLeft {
+			return nil, errors.New("snapshot has an impossible number of hints left")
+		}
+		gs.HintsLeft = snap.HintsLeft
+	}
+	gs.MaxNumFails = snap.FailsLeft
+	if gs.MaxNumFails == 0 {
+		gs.setState(LOSS)
+	}
+	for _, r := range snap.Input {
+		gs.PushRune(r)
+	}
+
+	return gs, nil
+}
+
+func foldingToStrings(folding map[rune]rune) map[string]string {
+	if folding == nil {
+		return nil
+	}
+	letters := map[string]string{}
+	for from, to := range folding {
+		letters[string(from)] = string(to)
+	}
+	return letters
+}
+
+func foldingFromStrings(letters map[string]string) map[rune]rune {
+	if letters == nil {
+		return nil
+	}
+	folding := map[rune]rune{}
+	for from, to := range letters {
+		if from == "" || to == "" {
+			continue
+		}
+		folding[[]rune(from)[0]] = []rune(to)[0]
+	}
+	return folding
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+)
+
+// SeededTarget deterministically picks a target from words. The same seed and the same
+// set of words always give the same target, regardless of the order of words.
+func SeededTarget(words []string, seed uint64) string {
+	sorted := slices.Clone(words)
+	slices.Sort(sorted)
+
+	h := fnv.New64a()
+	for _, word := range sorted {
+		h.Write([]byte(word))
+		h.Write([]byte{'\n'})
+	}
+	binary.Write(h, binary.BigEndian, seed)
+
+	return sorted[h.Sum64()%uint64(len(sorted))]
+}
+
+// DailySeed derives the seed of the daily puzzle from the calendar date and word length.
+// The date is taken in UTC, so everyone gets a new word at the same moment wherever they are.
+// It is kept to 32 bits so it fits in a puzzle code.
+func DailySeed(date time.Time, wordLen int) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(date.UTC().Format(time.DateOnly)))
+	binary.Write(h, binary.BigEndian, int64(wordLen))
+	return h.Sum32()
+}
+
+// DailyTarget is the word of the day for the given date.
+func DailyTarget(words []string, wordLen int, date time.Time) string {
+	return SeededTarget(words, uint64(DailySeed(date, wordLen)))
+}
+
+// SeededTargets picks n distinct targets the same way SeededTarget does. The first is
+// always SeededTarget(words, seed).
+func SeededTargets(words []string, seed uint64, n int) ([]string, error) {
+	if len(words) < n {
+		return nil, fmt.Errorf("not enough answers for %d boards", n)
+	}
+	targets := []string{}
+	// the offset is capped in case words holds duplicates
+	for offset := uint64(0); len(targets) < n && offset < uint64(len(words)*n); offset++ {
+		target := SeededTarget(words, seed+offset)
+		if !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) < n {
+		return nil, fmt.Errorf("not enough distinct answers for %d boards", n)
+	}
+	return targets, nil
+}
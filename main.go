@@ -2,71 +2,477 @@ package main
 
 import (
 	_ "embed"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/config"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
 	"gitlab.com/daneofmanythings/wohrdle/render"
+	"gitlab.com/daneofmanythings/wohrdle/save"
+	"gitlab.com/daneofmanythings/wohrdle/share"
 	"gitlab.com/daneofmanythings/wohrdle/states"
 	"gitlab.com/daneofmanythings/wohrdle/static"
+	"gitlab.com/daneofmanythings/wohrdle/stats"
 	"gitlab.com/daneofmanythings/wohrdle/utils"
 )
 
-const wordRepoPath string = "./static/words.json"
+const (
+	saveFileName     string = "save.json"
+	settingsFileName string = "settings.json" // the settings of the last game started
+
+	tickInterval time.Duration = 250 * time.Millisecond
+
+	usage string = `usage: wohrdle [flags]
+       wohrdle wordlist <command> [flags]
+       wohrdle solve [flags]
+       wohrdle serve [flags]
+       wohrdle join HOST:PORT [flags]
+
+Settings given as flags win over the config and the settings remembered from the last game.
+
+flags:`
+)
+
+// app holds what every screen of the application loop needs
+type app struct {
+	parameters   *states.Parameters
+	store        *stats.Store
+	savePath     string
+	settingsPath string
+
+	shareTo string   // clipboard, stdout or a file path
+	output  []string // printed once the screen is gone
+
+	renderer *render.Renderer
+	screen   tcell.Screen
+}
 
 func main() {
-	screen, err := render.CreateScreen()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "wordlist":
+			os.Exit(runWordlist(os.Args[2:]))
+		case "solve":
+			os.Exit(runSolve(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "join":
+			os.Exit(runJoin(os.Args[2:]))
+		}
+	}
+
+	daily := flag.Bool("daily", false, "play the word of the day")
+	puzzleCode := flag.String("puzzle", "", "play the puzzle with the given `CODE`")
+	wordsPath := flag.String("words", "", "play with the word list at `PATH`, either JSON or one word per line")
+	shareTo := flag.String("share", "clipboard", "where [s]hare puts results: clipboard, stdout (printed after exit) or a file `PATH`")
+	wordLen := flag.Int("len", 0, "play words of `N` letters")
+	numGuesses := flag.Int("guesses", 0, "allow `N` guesses")
+	numFails := flag.Int("fails", 0, "allow `N` failed words")
+	numBoards := flag.Int("boards", 0, "play `N` boards at once")
+	numHints := flag.Int("hints", 0, "allow `N` hints")
+	hardMode := flag.Bool("hard", false, "play in hard-mode")
+	mode := flag.String("mode", "", "play `MODE`: classic, countdown, speedrun or survival")
+	language := flag.String("lang", "", "play in `LANGUAGE`: english, spanish, german or portuguese")
+	foldAccents := flag.Bool("fold", true, "type accented letters as the plain letters they fold to, e.g. e for é")
+	noMenu := flag.Bool("no-menu", false, "start a game straight away and quit once it is left")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// only the flags that were given change a setting
+	flagged := config.Defaults{}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "len":
+			flagged.WordLen = wordLen
+		case "guesses":
+			flagged.NumGuesses = numGuesses
+		case "fails":
+			flagged.NumFails = numFails
+		case "boards":
+			flagged.NumBoards = numBoards
+		case "hints":
+			flagged.NumHints = numHints
+		case "hard":
+			flagged.HardMode = hardMode
+		case "mode":
+			flagged.Mode = mode
+		case "lang":
+			flagged.Language = language
+		case "fold":
+			flagged.FoldAccents = foldAccents
+		}
+	})
+
+	cfg := loadConfig()
+	if *wordsPath == "" {
+		*wordsPath = cfg.WordsPath()
+	}
+	languages, err := loadLanguages(*wordsPath)
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "could not load the word list: %v\n", err)
+		os.Exit(2)
+	}
+	dataDir, err := utils.DataDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not find a data directory: %v\n", err)
+		os.Exit(1)
+	}
+	settingsPath := filepath.Join(dataDir, settingsFileName)
+
+	themes := loadThemes(cfg)
+	parameters := loadParameters(cfg, languages, themes, settingsPath)
+	if err := parameters.ApplyDefaults(flagged); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags:\n%v\nsee wohrdle --help\n", err)
+		os.Exit(2)
+	}
+	if *daily {
+		parameters.Fields[states.FIELD_DAILY].Value = states.TRUE
+	}
+	if *puzzleCode != "" {
+		code, err := puzzle.Parse(*puzzleCode)
+		if err == nil {
+			err = parameters.ApplyPuzzleCode(code)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid puzzle code %q: %v\n", *puzzleCode, err)
+			os.Exit(2)
+		}
 	}
-	defer screen.Fini()
 
-	renderer := render.NewRenderer()
+	a := &app{
+		parameters:   parameters,
+		store:        stats.NewStore(dataDir),
+		savePath:     filepath.Join(dataDir, saveFileName),
+		settingsPath: settingsPath,
+		shareTo:      *shareTo,
+		renderer:     render.NewRenderer(themes),
+	}
+	a.parameters.HasSavedGame = save.Exists(a.savePath)
 
-	// wordRepo, err := utils.LoadWordRepoFromJSON(wordRepoPath)
-	wordRepo, err := utils.LoadEmbeddedWordRepo(static.WordRepoBytes)
+	a.screen, err = render.CreateScreen()
 	if err != nil {
 		panic(err)
 	}
-	parameters := states.NewDefaultParameters(wordRepo.Words)
+	defer a.screen.Fini()
+
+	a.postSignals()
+
+	if *noMenu {
+		if err := a.startGame(); err != nil {
+			a.screen.Fini()
+			fmt.Fprintf(os.Stderr, "could not start the game: %v\n", err)
+			os.Exit(1)
+		}
+		a.quit()
+	}
 
 	// the application loop
 	for {
-		runMainMenu(parameters, renderer, screen)
-		gs := states.NewGameSession(parameters)
-		for {
-			if shouldRunMenu := runGameSession(gs, renderer, screen); shouldRunMenu {
-				break
+		switch a.runMainMenu() {
+		case states.MENU_QUIT:
+			a.quit()
+		case states.MENU_STATS:
+			a.runStatsScreen(states.NewStatsScreen(a.store, a.parameters.Keymap))
+		case states.MENU_START:
+			if err := a.startGame(); err != nil {
+				a.parameters.Message = "could not start the game: " + err.Error()
 			}
+		case states.MENU_RESUME:
+			gs, err := states.LoadGameSession(a.savePath, a.parameters)
+			// a save is only resumed once. it is written again if the game is suspended again
+			save.Remove(a.savePath)
+			a.parameters.HasSavedGame = false
+			if err != nil {
+				a.parameters.Message = "could not resume the last game: " + err.Error()
+				continue
+			}
+			a.playGameSession(gs)
 		}
 	}
 }
 
-func runGameSession(gs *states.GameSession, r *render.Renderer, s tcell.Screen) bool {
+// loadConfig reads the user's config. Problems with it stop the app before anything is drawn.
+func loadConfig() config.Config {
+	configDir, err := utils.ConfigDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not find a config directory: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load(config.Path(configDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load the config: %v\n", err)
+		os.Exit(2)
+	}
+	return cfg
+}
+
+// loadThemes are the built-in themes and those of the config.
+func loadThemes(cfg config.Config) []render.Theme {
+	themes, err := render.LoadThemes(cfg.Themes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid theme in the config: %v\n", err)
+		os.Exit(2)
+	}
+	return themes
+}
+
+// loadParameters are the settings of the menu. The config gives the defaults, and the settings
+// remembered from the last game, which are read from settingsPath, win over them.
+func loadParameters(cfg config.Config, languages []states.Language, themes []render.Theme, settingsPath string) *states.Parameters {
+	parameters, err := states.NewParameters(languages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not load the word list: %v\n", err)
+		os.Exit(2)
+	}
+	parameters.ThemeNames = render.ThemeNames(themes)
+	parameters.KeyboardNames = render.KeyboardNames()
+	parameters.Keymap, err = states.LoadKeymap(cfg.Keys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid keys in the config:\n%v\n", err)
+		os.Exit(2)
+	}
+	if err := parameters.ApplyDefaults(cfg.Defaults); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid defaults in the config:\n%v\n", err)
+		os.Exit(2)
+	}
+	if last, err := config.LoadDefaults(settingsPath); err == nil {
+		// remembered settings that no longer fit, e.g. after changing word lists, are dropped
+		parameters.ApplyDefaults(last)
+	}
+	return parameters
+}
+
+// loadWordRepo loads a custom word list, or the embedded one when path is empty.
+func loadWordRepo(path string) (utils.WordRepository, error) {
+	if path == "" {
+		return utils.LoadEmbeddedWordRepo(static.WordRepoBytes)
+	}
+	return utils.LoadWordRepoFromFile(path)
+}
+
+// loadLanguages are the embedded word lists, or just the custom one when path is given.
+func loadLanguages(path string) ([]states.Language, error) {
+	if path != "" {
+		wordRepo, err := utils.LoadWordRepoFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []states.Language{{Name: states.CUSTOM_LANGUAGE, Repo: wordRepo}}, nil
+	}
+	languages := []states.Language{}
+	for _, l := range static.Languages {
+		wordRepo, err := utils.LoadEmbeddedWordRepo(l.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", l.Name, err)
+		}
+		languages = append(languages, states.Language{Code: l.Code, Name: l.Name, Repo: wordRepo})
+	}
+	return languages, nil
+}
+
+// postSignals turns signals into events so each loop can leave cleanly.
+func (a *app) postSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		for sig := range signals {
+			a.screen.PostEvent(tcell.NewEventInterrupt(sig))
+		}
+	}()
+}
+
+func (a *app) quit() {
+	a.screen.Fini()
+	for _, text := range a.output {
+		fmt.Println(text)
+	}
+	os.Exit(0)
+}
+
+// share puts the result of a finished game where the --share flag says.
+func (a *app) share(gs *states.GameSession) {
+	text := gs.ShareText()
+	switch a.shareTo {
+	case "clipboard":
+		if err := share.WriteClipboard(os.Stdout, text); err != nil {
+			gs.Shared("Could not copy the result: " + err.Error())
+			return
+		}
+		gs.Shared("Result copied to the clipboard.")
+	case "stdout":
+		a.output = append(a.output, text)
+		gs.Shared("Result will be printed on exit.")
+	default:
+		if err := os.WriteFile(a.shareTo, []byte(text+"\n"), 0o644); err != nil {
+			gs.Shared("Could not write the result: " + err.Error())
+			return
+		}
+		gs.Shared("Result written to " + a.shareTo + ".")
+	}
+}
+
+func (a *app) saveGameSession(gs *states.GameSession) error {
+	if err := states.SaveGameSession(a.savePath, gs); err != nil {
+		return err
+	}
+	a.parameters.HasSavedGame = true
+	return nil
+}
+
+// startGame plays a new game with the settings of the menu, which are remembered for next time.
+func (a *app) startGame() error {
+	gs, err := states.NewGameSession(a.parameters)
+	if err != nil {
+		return err
+	}
+	if err := config.WriteDefaults(a.settingsPath, a.parameters.Defaults()); err != nil {
+		gs.HelpText = "could not remember the settings: " + err.Error()
+	}
+	a.playGameSession(gs)
+	return nil
+}
+
+func (a *app) playGameSession(gs *states.GameSession) {
+	a.renderer.UseTheme(gs.Parameters.Theme())
+	if gs.IsTimed() {
+		stop := a.startTicker()
+		defer close(stop)
+	}
+	for {
+		if shouldRunMenu := a.runGameSession(gs); shouldRunMenu {
+			break
+		}
+	}
+	if gs.Suspended {
+		if err := a.saveGameSession(gs); err != nil {
+			a.parameters.Message = "could not save the game: " + err.Error()
+		}
+	}
+}
+
+func (a *app) runGameSession(gs *states.GameSession) bool {
 	for {
 		// the game loop
-		r.DrawGameSession(s, gs)
-		switch ev := s.PollEvent().(type) {
+		a.renderer.DrawGameSession(a.screen, gs)
+		switch ev := a.screen.PollEvent().(type) {
 		case *tcell.EventResize:
-			s.Sync()
+			a.screen.Sync()
+		case *tcell.EventInterrupt:
+			if gs.GetState() == engine.ACTIVE {
+				if err := a.saveGameSession(gs); err != nil {
+					// the screen is gone once the app quits, so the error is printed after it
+					a.screen.Fini()
+					fmt.Fprintf(os.Stderr, "could not save the game: %v\n", err)
+				}
+			}
+			a.quit()
+		case *states.EventTick:
+			wasActive := gs.GetState() == engine.ACTIVE
+			gs.Tick(ev.When())
+			a.recordIfEnded(gs, wasActive)
 		case *tcell.EventKey:
+			wasActive := gs.GetState() == engine.ACTIVE
 			if shouldExit := gs.HandleEventKey(ev); shouldExit {
 				return true
 			}
+			if gs.Sharing {
+				gs.Sharing = false
+				a.share(gs)
+			}
+			a.recordIfEnded(gs, wasActive)
+		case *tcell.EventMouse:
+			wasActive := gs.GetState() == engine.ACTIVE
+			x, y := ev.Position()
+			letter, _ := a.renderer.KeyAt(a.screen, gs, x, y)
+			gs.HandleEventMouse(ev, letter)
+			a.recordIfEnded(gs, wasActive)
+		case *tcell.EventPaste:
+			gs.HandleEventPaste(ev)
 		default:
 			// nothing
 		}
 	}
 }
 
-func runMainMenu(p *states.Parameters, r *render.Renderer, s tcell.Screen) {
+// recordIfEnded saves the statistics of a game that has just ended. A speedrun then moves on to its next word.
+func (a *app) recordIfEnded(gs *states.GameSession, wasActive bool) {
+	if !wasActive || gs.GetState() == engine.ACTIVE {
+		return
+	}
+	err := a.store.Append(gs.Record())
+	gs.Advance()
+	if err != nil {
+		gs.HelpText += " | stats not saved: " + err.Error()
+	}
+}
+
+// startTicker posts a tick to the screen every so often so timed games can redraw their
+// clock. Closing the returned channel stops it.
+func (a *app) startTicker() chan struct{} {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				a.screen.PostEvent(states.NewEventTick())
+			}
+		}
+	}()
+	return stop
+}
+
+func (a *app) runMainMenu() states.MenuAction {
 	for {
 		// the menu loop
-		r.DrawMenu(s, p)
+		a.renderer.UseTheme(a.parameters.Theme())
+		a.renderer.DrawMenu(a.screen, a.parameters)
+
+		switch ev := a.screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			a.screen.Sync()
+		case *tcell.EventInterrupt:
+			a.quit()
+		case *tcell.EventKey:
+			if action := a.parameters.HandleEventKey(ev); action != states.MENU_NONE {
+				return action
+			}
+		case *tcell.EventMouse:
+			_, y := ev.Position()
+			a.parameters.HandleEventMouse(ev, a.renderer.MenuFieldAt(a.screen, a.parameters, y))
+		case *tcell.EventPaste:
+			a.parameters.HandleEventPaste(ev)
+		default:
+			// nothing
+		}
+	}
+}
+
+func (a *app) runStatsScreen(ss *states.StatsScreen) {
+	for {
+		// the stats loop
+		a.renderer.DrawStats(a.screen, ss)
 
-		switch ev := s.PollEvent().(type) {
+		switch ev := a.screen.PollEvent().(type) {
 		case *tcell.EventResize:
-			s.Sync()
+			a.screen.Sync()
+		case *tcell.EventInterrupt:
+			a.quit()
 		case *tcell.EventKey:
-			if shouldReturn := p.HandleEventKey(ev, s); shouldReturn {
+			if shouldReturn := ss.HandleEventKey(ev); shouldReturn {
 				return
 			}
 		default:
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/daneofmanythings/wohrdle/config"
+	"gitlab.com/daneofmanythings/wohrdle/render"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+func TestLoadParametersPrecedence(t *testing.T) {
+	languages := []states.Language{{Name: states.CUSTOM_LANGUAGE, Repo: utils.NewWordRepository(map[string][]string{"5": {"tests"}})}}
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	guesses, hints := 8, 2
+	if err := config.WriteDefaults(settingsPath, config.Defaults{NumGuesses: &guesses}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgGuesses := 4
+	cfg := config.Config{Defaults: config.Defaults{NumGuesses: &cfgGuesses, NumHints: &hints}}
+	params := loadParameters(cfg, languages, render.Themes, settingsPath)
+	if got := params.Fields[states.FIELD_NUM_GUESSES].Value; got != guesses {
+		t.Fatalf("the remembered setting did not win over the config. got=%d, expected=%d", got, guesses)
+	}
+	if got := params.NumHints(); got != hints {
+		t.Fatalf("the config did not set what was not remembered. got=%d, expected=%d", got, hints)
+	}
+}
@@ -0,0 +1,109 @@
+package multiplayer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Messages are JSON objects, one per line, told apart by their type.
+const (
+	// client to server
+	MSG_HELLO   string = "hello"   // the first message of a client. Name is who is joining
+	MSG_READY   string = "ready"   // the client wants to play the next round
+	MSG_GUESS   string = "guess"   // Word was scored by the client
+	MSG_GIVE_UP string = "give_up" // the client lost the round without using up its guesses
+
+	// server to client
+	MSG_WELCOME  string = "welcome"  // Name is the client's, made unique. Players are everyone connected
+	MSG_JOINED   string = "joined"   // Name connected
+	MSG_LEFT     string = "left"     // Name disconnected
+	MSG_START    string = "start"    // a round of Code started, played by Players
+	MSG_SCORED   string = "scored"   // Name scored a guess, shown as Row
+	MSG_REJECTED string = "rejected" // Word was not accepted by the server, see Error
+	MSG_FINISHED string = "finished" // Name is done with the round. Won or not, in Guesses
+	MSG_OVER     string = "over"     // everyone is done with the round. Winner solved Target first
+	MSG_ERROR    string = "error"    // the server could not go on with the client, see Error
+)
+
+// writeTimeout is how long a peer gets to take a message before it is dropped.
+const writeTimeout = 5 * time.Second
+
+// Message is everything the client and server say to each other. Only the fields of its
+// type are set.
+type Message struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name,omitempty"`
+	Players []string `json:"players,omitempty"`
+	Round   int      `json:"round,omitempty"`
+	Code    string   `json:"code,omitempty"` // the puzzle code, see puzzle.Parse
+	Word    string   `json:"word,omitempty"`
+	Row     string   `json:"row,omitempty"` // the colors of a scored guess, see solver.FormatFeedback
+	Won     bool     `json:"won,omitempty"`
+	Guesses int      `json:"guesses,omitempty"`
+	Winner  string   `json:"winner,omitempty"`
+	Target  string   `json:"target,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Conn sends and receives messages over a connection. Sending is safe from several goroutines.
+type Conn struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn:    conn,
+		scanner: bufio.NewScanner(conn),
+		enc:     json.NewEncoder(conn),
+	}
+}
+
+// Send writes msg as a line. A peer that does not take it in time gets an error.
+func (c *Conn) Send(msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.enc.Encode(msg)
+}
+
+// Receive waits for the next message. Lines that are not a message are an error.
+func (c *Conn) Receive() (Message, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return Message{}, err
+		}
+		return Message{}, net.ErrClosed
+	}
+	msg := Message{}
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		return Message{}, fmt.Errorf("malformed message: %w", err)
+	}
+	return msg, nil
+}
+
+// Close closes the connection, which ends any Receive waiting on it.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Dial connects to the server at addr and joins as name. The server answers with MSG_WELCOME.
+func Dial(addr, name string) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, writeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := NewConn(conn)
+	if err := c.Send(Message{Type: MSG_HELLO, Name: name}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,326 @@
+package multiplayer
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
+	"gitlab.com/daneofmanythings/wohrdle/solver"
+)
+
+// MAX_NAME_LEN is the longest a player name can be. Longer names are cut.
+const MAX_NAME_LEN int = 16
+
+// outboxSize is how many messages a player can fall behind before it is disconnected.
+const outboxSize = 64
+
+// Server runs versus games. Every player of a round races to the same target, which the server
+// picks, and each guess is scored again by the server on a headless game of its own. A round
+// starts once enough players are connected and all of them are ready.
+type Server struct {
+	MinPlayers int // the players a round waits for. at least 1
+	// NewRound picks the puzzle of a round along with the engine configuration it is played
+	// with. every player builds the same game from the code
+	NewRound func() (puzzle.Code, engine.Config, error)
+	Log      io.Writer // where joins, leaves and rounds are reported. nothing when nil
+
+	mu       sync.Mutex
+	listener net.Listener
+	players  []*player
+	round    int
+	playing  bool // a round is being played
+	winner   string
+	target   string
+	closed   bool
+}
+
+type player struct {
+	name    string
+	conn    *Conn
+	ready   bool
+	session *engine.GameSession // nil when not playing the current round
+	outbox  chan Message        // written by the player's own goroutine, so the lock is never held on a write
+}
+
+// Serve accepts players on l until the server is closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go s.handle(NewConn(conn))
+	}
+}
+
+// Close stops accepting players and disconnects everyone.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, p := range s.players {
+		p.conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// handle talks to a player until they disconnect.
+func (s *Server) handle(conn *Conn) {
+	defer conn.Close()
+	hello, err := conn.Receive()
+	if err != nil {
+		return
+	}
+	if hello.Type != MSG_HELLO {
+		conn.Send(Message{Type: MSG_ERROR, Error: "expected " + MSG_HELLO})
+		return
+	}
+
+	p := s.join(conn, hello.Name)
+	if p == nil {
+		return
+	}
+	defer s.leave(p)
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		switch msg.Type {
+		case MSG_READY:
+			p.ready = true
+			s.maybeStart()
+		case MSG_GUESS:
+			s.guess(p, msg.Word)
+		case MSG_GIVE_UP:
+			if p.session != nil && p.session.GetState() == engine.ACTIVE {
+				p.session.GiveUp()
+				s.finish(p)
+			}
+		default:
+			s.send(p, Message{Type: MSG_ERROR, Error: fmt.Sprintf("unknown message %q", msg.Type)})
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) join(conn *Conn, name string) *player {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	p := &player{name: s.uniqueName(cleanName(name)), conn: conn, outbox: make(chan Message, outboxSize)}
+	go p.write()
+	s.players = append(s.players, p)
+	s.logf("%s joined", p.name)
+	s.send(p, Message{Type: MSG_WELCOME, Name: p.name, Players: s.names()})
+	s.broadcast(p, Message{Type: MSG_JOINED, Name: p.name})
+	return p
+}
+
+func (s *Server) leave(p *player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := slices.Index(s.players, p)
+	if idx == -1 {
+		return
+	}
+	s.players = slices.Delete(s.players, idx, idx+1)
+	close(p.outbox)
+	s.logf("%s left", p.name)
+	s.broadcast(nil, Message{Type: MSG_LEFT, Name: p.name})
+	// the round, or the next one, may have only been waiting on them
+	s.maybeEnd()
+	s.maybeStart()
+}
+
+// maybeStart starts a round once enough players are connected and all of them are ready.
+func (s *Server) maybeStart() {
+	if s.playing || len(s.players) < max(s.MinPlayers, 1) {
+		return
+	}
+	for _, p := range s.players {
+		if !p.ready {
+			return
+		}
+	}
+
+	code, cfg, err := s.NewRound()
+	if err != nil {
+		s.logf("could not start a round: %v", err)
+		s.broadcast(nil, Message{Type: MSG_ERROR, Error: "could not start a round: " + err.Error()})
+		return
+	}
+	sessions := []*engine.GameSession{}
+	for range s.players {
+		session, err := engine.NewGameSession(cfg)
+		if err != nil {
+			s.logf("could not start a round: %v", err)
+			s.broadcast(nil, Message{Type: MSG_ERROR, Error: "could not start a round: " + err.Error()})
+			return
+		}
+		sessions = append(sessions, session)
+	}
+	for i, p := range s.players {
+		p.session = sessions[i]
+		p.ready = false
+	}
+	s.round += 1
+	s.playing = true
+	s.winner = ""
+	s.target = s.players[0].session.Target()
+	s.logf("round %d started with %s", s.round, strings.Join(s.names(), ", "))
+	s.broadcast(nil, Message{Type: MSG_START, Round: s.round, Code: code.String(), Players: s.names()})
+}
+
+// guess scores word for p and shows everyone its colors.
+func (s *Server) guess(p *player, word string) {
+	if p.session == nil || p.session.GetState() != engine.ACTIVE {
+		s.send(p, Message{Type: MSG_REJECTED, Word: word, Error: "not playing"})
+		return
+	}
+	res, err := p.session.Guess(word)
+	switch {
+	case err != nil && res.State == engine.ACTIVE:
+		s.send(p, Message{Type: MSG_REJECTED, Word: word, Error: err.Error()})
+		return
+	case err == nil:
+		states := []engine.CellState{}
+		for _, cell := range res.Rows[0] {
+			states = append(states, cell.GetState())
+		}
+		s.broadcast(nil, Message{Type: MSG_SCORED, Name: p.name, Row: solver.FormatFeedback(states)})
+	}
+	if p.session.GetState() != engine.ACTIVE {
+		s.finish(p)
+	}
+}
+
+// finish reports that p is done with the round. The first to solve it wins.
+func (s *Server) finish(p *player) {
+	won := p.session.GetState() == engine.VICTORY
+	if won && s.winner == "" {
+		s.winner = p.name
+	}
+	s.broadcast(nil, Message{Type: MSG_FINISHED, Name: p.name, Won: won, Guesses: p.session.GuessesUsed()})
+	s.maybeEnd()
+}
+
+// maybeEnd ends the round once every player of it is done or gone.
+func (s *Server) maybeEnd() {
+	if !s.playing {
+		return
+	}
+	for _, p := range s.players {
+		if p.session != nil && p.session.GetState() == engine.ACTIVE {
+			return
+		}
+	}
+	for _, p := range s.players {
+		p.session = nil
+	}
+	s.playing = false
+	if s.winner != "" {
+		s.logf("round %d won by %s", s.round, s.winner)
+	} else {
+		s.logf("round %d not solved", s.round)
+	}
+	s.broadcast(nil, Message{Type: MSG_OVER, Round: s.round, Winner: s.winner, Target: s.target})
+	// players that were ready early only wait for the others
+	s.maybeStart()
+}
+
+// send queues a message for p. A player that falls too far behind is disconnected, which ends
+// its handler.
+func (s *Server) send(p *player, msg Message) {
+	select {
+	case p.outbox <- msg:
+	default:
+		p.conn.Close()
+	}
+}
+
+// write sends the messages queued for p until it leaves. A slow player only holds up itself.
+func (p *player) write() {
+	for msg := range p.outbox {
+		if err := p.conn.Send(msg); err != nil {
+			p.conn.Close()
+		}
+	}
+}
+
+// broadcast sends msg to every player but except.
+func (s *Server) broadcast(except *player, msg Message) {
+	for _, p := range s.players {
+		if p != except {
+			s.send(p, msg)
+		}
+	}
+}
+
+func (s *Server) names() []string {
+	names := []string{}
+	for _, p := range s.players {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// uniqueName numbers name when someone connected already goes by it.
+func (s *Server) uniqueName(name string) string {
+	names := s.names()
+	unique := name
+	for n := 2; slices.Contains(names, unique); n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	return unique
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Log != nil {
+		fmt.Fprintf(s.Log, format+"\n", args...)
+	}
+}
+
+// cleanName keeps the printable part of a name, cut to MAX_NAME_LEN. Blank names are "player".
+func cleanName(name string) string {
+	clean := []rune{}
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsPrint(r) {
+			clean = append(clean, r)
+		}
+	}
+	if len(clean) > MAX_NAME_LEN {
+		clean = clean[:MAX_NAME_LEN]
+	}
+	if len(strings.TrimSpace(string(clean))) == 0 {
+		return "player"
+	}
+	return strings.TrimSpace(string(clean))
+}
@@ -0,0 +1,206 @@
+package multiplayer
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
+)
+
+func mockNewServer(t *testing.T, minPlayers int) string {
+	s := mockServer(minPlayers)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return l.Addr().String()
+}
+
+func mockServer(minPlayers int) *Server {
+	words := []string{"crane", "slate", "toast"}
+	return &Server{
+		MinPlayers: minPlayers,
+		NewRound: func() (puzzle.Code, engine.Config, error) {
+			code := puzzle.Code{Seed: 1, WordLen: 5, NumGuesses: 6, MaxNumFails: 2, NumBoards: 1}
+			return code, engine.Config{
+				WordLen:     5,
+				NumGuesses:  6,
+				MaxNumFails: 2,
+				Words:       words,
+				Targets:     []string{"crane"},
+			}, nil
+		},
+	}
+}
+
+func mockDial(t *testing.T, addr, name string) *Conn {
+	c, err := Dial(addr, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// expect reads the next message, which must be of type msgType.
+func expect(t *testing.T, c *Conn, msgType string) Message {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	msg, err := c.Receive()
+	if err != nil {
+		t.Fatalf("no %q message. err=%v", msgType, err)
+	}
+	if msg.Type != msgType {
+		t.Fatalf("unexpected message. got=%+v, expected type=%q", msg, msgType)
+	}
+	return msg
+}
+
+func TestRace(t *testing.T) {
+	addr := mockNewServer(t, 2)
+	ann := mockDial(t, addr, "ann")
+	if msg := expect(t, ann, MSG_WELCOME); msg.Name != "ann" || len(msg.Players) != 1 {
+		t.Fatalf("unexpected welcome. got=%+v", msg)
+	}
+	ann.Send(Message{Type: MSG_READY})
+
+	// the same name is numbered
+	bob := mockDial(t, addr, "ann")
+	if msg := expect(t, bob, MSG_WELCOME); msg.Name != "ann2" || len(msg.Players) != 2 {
+		t.Fatalf("unexpected welcome. got=%+v", msg)
+	}
+	expect(t, ann, MSG_JOINED)
+	bob.Send(Message{Type: MSG_READY})
+
+	for _, c := range []*Conn{ann, bob} {
+		msg := expect(t, c, MSG_START)
+		if msg.Round != 1 || len(msg.Players) != 2 {
+			t.Fatalf("unexpected start. got=%+v", msg)
+		}
+		if _, err := puzzle.Parse(msg.Code); err != nil {
+			t.Fatalf("the start has no puzzle code. err=%v", err)
+		}
+	}
+
+	ann.Send(Message{Type: MSG_GUESS, Word: "slate"})
+	for _, c := range []*Conn{ann, bob} {
+		if msg := expect(t, c, MSG_SCORED); msg.Name != "ann" || msg.Row != "..g.g" {
+			t.Fatalf("unexpected scored row. got=%+v", msg)
+		}
+	}
+	ann.Send(Message{Type: MSG_GUESS, Word: "wrong"})
+	if msg := expect(t, ann, MSG_REJECTED); msg.Error != engine.ErrInvalidWord.Error() {
+		t.Fatalf("unexpected rejection. got=%+v", msg)
+	}
+
+	bob.Send(Message{Type: MSG_GUESS, Word: "CRANE"})
+	for _, c := range []*Conn{ann, bob} {
+		expect(t, c, MSG_SCORED)
+		if msg := expect(t, c, MSG_FINISHED); msg.Name != "ann2" || !msg.Won || msg.Guesses != 1 {
+			t.Fatalf("unexpected finish. got=%+v", msg)
+		}
+	}
+
+	// being ready before the round is over plays the next one as soon as everyone is
+	bob.Send(Message{Type: MSG_READY})
+	ann.Send(Message{Type: MSG_GIVE_UP})
+	for _, c := range []*Conn{ann, bob} {
+		if msg := expect(t, c, MSG_FINISHED); msg.Name != "ann" || msg.Won {
+			t.Fatalf("unexpected finish. got=%+v", msg)
+		}
+		if msg := expect(t, c, MSG_OVER); msg.Winner != "ann2" || msg.Target != "CRANE" {
+			t.Fatalf("unexpected end of the round. got=%+v", msg)
+		}
+	}
+
+	ann.Send(Message{Type: MSG_READY})
+	if msg := expect(t, ann, MSG_START); msg.Round != 2 {
+		t.Fatalf("unexpected round. got=%d, expected=%d", msg.Round, 2)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	addr := mockNewServer(t, 2)
+	ann := mockDial(t, addr, "ann")
+	expect(t, ann, MSG_WELCOME)
+	ann.Send(Message{Type: MSG_READY})
+	bob := mockDial(t, addr, "bob")
+	expect(t, bob, MSG_WELCOME)
+	expect(t, ann, MSG_JOINED)
+	bob.Send(Message{Type: MSG_READY})
+	expect(t, ann, MSG_START)
+	expect(t, bob, MSG_START)
+
+	bob.Close()
+	if msg := expect(t, ann, MSG_LEFT); msg.Name != "bob" {
+		t.Fatalf("unexpected leave. got=%+v", msg)
+	}
+
+	// the round goes on without them
+	ann.Send(Message{Type: MSG_GUESS, Word: "crane"})
+	expect(t, ann, MSG_SCORED)
+	expect(t, ann, MSG_FINISHED)
+	if msg := expect(t, ann, MSG_OVER); msg.Winner != "ann" {
+		t.Fatalf("unexpected winner. got=%q, expected=%q", msg.Winner, "ann")
+	}
+}
+
+func TestSlowPlayer(t *testing.T) {
+	s := mockServer(2)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+
+	// a player that never reads can not take its welcome
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	go s.handle(NewConn(server))
+	if err := NewConn(client).Send(Message{Type: MSG_HELLO, Name: "slow"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// everyone else is still answered right away
+	ann := mockDial(t, l.Addr().String(), "ann")
+	if msg := expect(t, ann, MSG_WELCOME); len(msg.Players) != 2 {
+		t.Fatalf("unexpected players. got=%v", msg.Players)
+	}
+}
+
+func TestHelloFirst(t *testing.T) {
+	addr := mockNewServer(t, 1)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewConn(conn)
+	t.Cleanup(func() { c.Close() })
+	c.Send(Message{Type: MSG_READY})
+	if msg := expect(t, c, MSG_ERROR); msg.Error != "expected hello" {
+		t.Fatalf("unexpected error. got=%q", msg.Error)
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"ann", "ann"},
+		{"  ann\x07 ", "ann"},
+		{"", "player"},
+		{"averyveryverylongname", "averyveryverylon"},
+	}
+
+	for _, tc := range testCases {
+		if got := cleanName(tc.name); got != tc.expected {
+			t.Fatalf("unexpected name. got=%q, expected=%q", got, tc.expected)
+		}
+	}
+}
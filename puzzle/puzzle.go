@@ -0,0 +1,138 @@
+package puzzle
+
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+// version is bumped whenever the layout of an encoded code changes. older codes still parse
+const version byte = 3
+
+// codeLens is the number of bytes in a code of each version, checksum included
+var codeLens = map[byte]int{1: 10, 2: 11, 3: 13}
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var (
+	ErrMalformed   = errors.New("malformed puzzle code")
+	ErrBadChecksum = errors.New("puzzle code checksum mismatch")
+	ErrVersion     = errors.New("puzzle code is from a different version")
+)
+
+// Code is everything needed to reproduce a game: its settings and the seed its target is drawn with.
+type Code struct {
+	Seed        uint32
+	WordLen     int
+	NumGuesses  int
+	MaxNumFails int
+	HardMode    bool
+	NumBoards   int
+	Absurd      bool
+	Language    string // the two letter code of the word list. empty for custom lists and older codes
+}
+
+// Config builds the engine configuration for the puzzle from the answers and allowed
+// guesses of its length.
+func (c Code) Config(answers, allowed []string) (engine.Config, error) {
+	var targets []string
+	if !c.Absurd {
+		var err error
+		targets, err = engine.SeededTargets(answers, uint64(c.Seed), max(c.NumBoards, 1))
+		if err != nil {
+			return engine.Config{}, err
+		}
+	}
+	return engine.Config{
+		WordLen:     c.WordLen,
+		NumGuesses:  c.NumGuesses,
+		MaxNumFails: c.MaxNumFails,
+		HardMode:    c.HardMode,
+		NumBoards:   max(c.NumBoards, 1),
+		Absurd:      c.Absurd,
+		Words:       allowed,
+		Answers:     answers,
+		Targets:     targets,
+	}, nil
+}
+
+// String encodes the code. The target is never part of it.
+func (c Code) String() string {
+	var flags byte
+	if c.HardMode {
+		flags |= 1
+	}
+	if c.Absurd {
+		flags |= 2
+	}
+	b := []byte{
+		version,
+		byte(c.WordLen),
+		byte(c.NumGuesses),
+		byte(c.MaxNumFails),
+		flags,
+		byte(max(c.NumBoards, 1)),
+		byte(c.Seed >> 24),
+		byte(c.Seed >> 16),
+		byte(c.Seed >> 8),
+		byte(c.Seed),
+	}
+	lang := [2]byte{}
+	copy(lang[:], c.Language)
+	b = append(b, lang[:]...)
+	b = append(b, checksum(b))
+	return encoding.EncodeToString(b)
+}
+
+// Parse decodes a code made by String. It is forgiving of case, spaces and dashes.
+func Parse(s string) (Code, error) {
+	s = strings.ToUpper(s)
+	s = strings.NewReplacer(" ", "", "-", "").Replace(s)
+
+	b, err := encoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return Code{}, ErrMalformed
+	}
+	codeLen, ok := codeLens[b[0]]
+	if !ok {
+		return Code{}, ErrVersion
+	}
+	if len(b) != codeLen {
+		return Code{}, ErrMalformed
+	}
+	if checksum(b[:codeLen-1]) != b[codeLen-1] {
+		return Code{}, ErrBadChecksum
+	}
+
+	c := Code{
+		WordLen:     int(b[1]),
+		NumGuesses:  int(b[2]),
+		MaxNumFails: int(b[3]),
+		HardMode:    b[4]&1 != 0,
+		Absurd:      b[4]&2 != 0,
+		NumBoards:   1,
+	}
+	seed := b[5:9]
+	if b[0] >= 2 {
+		c.NumBoards = int(b[5])
+		seed = b[6:10]
+	}
+	c.Seed = uint32(seed[0])<<24 | uint32(seed[1])<<16 | uint32(seed[2])<<8 | uint32(seed[3])
+	if b[0] >= 3 {
+		c.Language = strings.TrimRight(string(b[10:12]), "\x00")
+	}
+	if c.WordLen < 1 || c.NumGuesses < 1 || c.MaxNumFails < 1 || c.NumBoards < 1 {
+		return Code{}, fmt.Errorf("%w: settings must be positive", ErrMalformed)
+	}
+	return c, nil
+}
+
+func checksum(b []byte) byte {
+	h := fnv.New32a()
+	h.Write(b)
+	return byte(h.Sum32())
+}
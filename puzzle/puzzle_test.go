@@ -0,0 +1,110 @@
+package puzzle
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []Code{
+		{Seed: 0, WordLen: 1, NumGuesses: 1, MaxNumFails: 1, NumBoards: 1},
+		{Seed: 0xdeadbeef, WordLen: 5, NumGuesses: 6, MaxNumFails: 5, HardMode: true, NumBoards: 1},
+		{Seed: 42, WordLen: 15, NumGuesses: 20, MaxNumFails: 20, NumBoards: 8},
+		{Seed: 7, WordLen: 5, NumGuesses: 6, MaxNumFails: 5, HardMode: true, NumBoards: 1, Absurd: true},
+		{Seed: 3, WordLen: 6, NumGuesses: 6, MaxNumFails: 5, NumBoards: 1, Language: "es"},
+	}
+
+	for _, tc := range testCases {
+		parsed, err := Parse(tc.String())
+		if err != nil {
+			t.Fatalf("could not parse %s: %v", tc.String(), err)
+		}
+		if parsed != tc {
+			t.Fatalf("round trip failed. got=%+v, expected=%+v", parsed, tc)
+		}
+	}
+}
+
+func TestParseIsForgiving(t *testing.T) {
+	code := Code{Seed: 1234, WordLen: 5, NumGuesses: 6, MaxNumFails: 5, NumBoards: 1}
+	s := code.String()
+
+	parsed, err := Parse(" " + s[:4] + "-" + s[4:] + " ")
+	if err != nil || parsed != code {
+		t.Fatalf("could not parse a dashed code. err=%v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	valid := Code{Seed: 1234, WordLen: 5, NumGuesses: 6, MaxNumFails: 5}.String()
+	tampered := []byte(valid)
+	if tampered[3] == 'A' {
+		tampered[3] = 'B'
+	} else {
+		tampered[3] = 'A'
+	}
+
+	testCases := []struct {
+		name     string
+		code     string
+		expected error
+	}{
+		{"empty", "", ErrMalformed},
+		{"notBase32", "hello!", ErrMalformed},
+		{"tooShort", valid[:8], ErrMalformed},
+		{"tampered", string(tampered), ErrBadChecksum},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse(tc.code); !errors.Is(err, tc.expected) {
+				t.Fatalf("unexpected error. got=%v, expected=%v", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConfigReproducesTarget(t *testing.T) {
+	words := []string{"tests", "toast", "adieu", "volts", "lusts"}
+	code := Code{Seed: 99, WordLen: 5, NumGuesses: 6, MaxNumFails: 5, NumBoards: 2}
+
+	first, err := code.Config(words, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := code.Config(words, words)
+	if !slices.Equal(first.Targets, second.Targets) || len(first.Targets) != 2 {
+		t.Fatalf("the same code gave different targets. first=%v, second=%v", first.Targets, second.Targets)
+	}
+}
+
+func TestParseVersionOne(t *testing.T) {
+	// a code shared before multiple boards existed
+	b := []byte{1, 5, 6, 5, 1, 0, 0, 4, 210}
+	b = append(b, checksum(b))
+
+	parsed, err := Parse(encoding.EncodeToString(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Code{Seed: 1234, WordLen: 5, NumGuesses: 6, MaxNumFails: 5, HardMode: true, NumBoards: 1}
+	if parsed != expected {
+		t.Fatalf("unexpected code. got=%+v, expected=%+v", parsed, expected)
+	}
+}
+
+func TestParseVersionTwo(t *testing.T) {
+	// a code shared before word lists had languages
+	b := []byte{2, 5, 6, 5, 0, 2, 0, 0, 4, 210}
+	b = append(b, checksum(b))
+
+	parsed, err := Parse(encoding.EncodeToString(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Code{Seed: 1234, WordLen: 5, NumGuesses: 6, MaxNumFails: 5, NumBoards: 2}
+	if parsed != expected {
+		t.Fatalf("unexpected code. got=%+v, expected=%+v", parsed, expected)
+	}
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+// Keyboard is a layout of the letter keys, top row first.
+type Keyboard struct {
+	Name string
+	Rows []string
+}
+
+// Keyboards are the layouts the seen letters can be shown in. The first one is the default.
+var Keyboards []Keyboard = []Keyboard{
+	{"qwerty", []string{"QWERTYUIOP", "ASDFGHJKL", "ZXCVBNM"}},
+	{"azerty", []string{"AZERTYUIOP", "QSDFGHJKLM", "WXCVBN"}},
+	{"dvorak", []string{"PYFGCRL", "AOEUIDHTNS", "QJKXBMWVZ"}},
+	{"alphabetical", []string{"ABCDEFGHI", "JKLMNOPQR", "STUVWXYZ"}},
+	{"qwertz", []string{"QWERTZUIOPÜ", "ASDFGHJKLÖÄ", "YXCVBNMß"}},
+}
+
+// KeyboardNames are the names of the layouts, in order.
+func KeyboardNames() []string {
+	names := make([]string, len(Keyboards))
+	for i, k := range Keyboards {
+		names[i] = k.Name
+	}
+	return names
+}
+
+// keyLayout is where the keys of a keyboard go within a given width.
+type keyLayout struct {
+	rows  [][]rune
+	pitch int // columns from one key to the next. 2 leaves a gap between keys
+	width int
+}
+
+// layoutKeys fits the keyboard into width. Only the keys of letters in seen, the alphabet of
+// the game, are shown, and letters of seen the layout lacks get a row of their own. Keys lose
+// their gaps when the rows do not fit, and rows are wrapped when they still do not.
+func layoutKeys(k Keyboard, seen []engine.Cell, width int) keyLayout {
+	rows := [][]rune{}
+	for _, row := range k.Rows {
+		keys := []rune{}
+		for _, key := range row {
+			if slices.ContainsFunc(seen, func(c engine.Cell) bool { return c.Char == key }) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			rows = append(rows, keys)
+		}
+	}
+	extra := []rune{}
+	for _, cell := range seen {
+		if !strings.ContainsRune(strings.Join(k.Rows, ""), cell.Char) {
+			extra = append(extra, cell.Char)
+		}
+	}
+	if len(extra) > 0 {
+		rows = append(rows, extra)
+	}
+
+	longest := 0
+	for _, row := range rows {
+		longest = max(longest, len(row))
+	}
+	width = max(width, 1)
+	if 2*longest-1 <= width {
+		return keyLayout{rows: rows, pitch: 2, width: 2*longest - 1}
+	}
+
+	wrapped := [][]rune{}
+	for _, row := range rows {
+		for len(row) > width {
+			wrapped = append(wrapped, row[:width])
+			row = row[width:]
+		}
+		wrapped = append(wrapped, row)
+	}
+	return keyLayout{rows: wrapped, pitch: 1, width: min(longest, width)}
+}
+
+// keyAt is where the key at row i, column j is drawn, relative to the centered layout.
+func (l keyLayout) keyAt(i, j int) (int, int) {
+	rowW := (len(l.rows[i])-1)*l.pitch + 1
+	return (l.width-rowW)/2 + j*l.pitch, i
+}
+
+// drawKeyboard draws the keys of l with x, y as the top left corner, each colored by what
+// seen knows about its letter.
+func (r *Renderer) drawKeyboard(s tcell.Screen, x, y int, l keyLayout, seen []engine.Cell) {
+	for i, row := range l.rows {
+		for j, key := range row {
+			style := tcell.StyleDefault
+			idx := slices.IndexFunc(seen, func(c engine.Cell) bool { return c.Char == key })
+			if idx != -1 {
+				switch state := seen[idx].GetState(); state {
+				case engine.USED:
+					style = r.theme.UsedKey
+				case engine.CORRECT, engine.PARTIAL:
+					style = r.theme.Cell(state)
+				}
+			}
+			kx, ky := l.keyAt(i, j)
+			s.SetContent(x+kx, y+ky, key, nil, style)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+func TestLayoutKeys(t *testing.T) {
+	seen := engine.NewSeenCharRecord(engine.AllRunes)
+	testCases := []struct {
+		name     string
+		width    int
+		pitch    int
+		numRows  int
+		expected int // the width of the layout
+	}{
+		{"spaced", 80, 2, 3, 19},
+		{"packed", 12, 1, 3, 10},
+		{"wrapped", 6, 1, 6, 6},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := layoutKeys(Keyboards[0], seen, tc.width)
+			if l.pitch != tc.pitch || len(l.rows) != tc.numRows || l.width != tc.expected {
+				t.Fatalf("wrong layout. got pitch=%d rows=%d width=%d, expected pitch=%d rows=%d width=%d",
+					l.pitch, len(l.rows), l.width, tc.pitch, tc.numRows, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLayoutKeysExtraLetters(t *testing.T) {
+	seen := append(engine.NewSeenCharRecord(engine.AllRunes), engine.Cell{Char: 'Ñ'})
+
+	l := layoutKeys(Keyboards[0], seen, 80)
+	if len(l.rows) != 4 || string(l.rows[3]) != "Ñ" {
+		t.Fatalf("the letter missing from the layout was not added. got=%q", l.rows)
+	}
+}
+
+func TestDrawKeyboard(t *testing.T) {
+	s := tcell.NewSimulationScreen("")
+	s.Init()
+	s.SetSize(40, 10)
+	r := NewRenderer(Themes)
+	b := engine.NewSeenCharRecord(engine.AllRunes)
+	for i := range b {
+		switch b[i].Char {
+		case 'Q':
+			b[i].SetState(engine.USED)
+		case 'W':
+			b[i].SetState(engine.CORRECT)
+		case 'E':
+			b[i].SetState(engine.PARTIAL)
+		}
+	}
+
+	r.drawKeyboard(s, 0, 0, layoutKeys(Keyboards[0], b, 40), b)
+	expected := []struct {
+		x     int
+		key   rune
+		style tcell.Style
+	}{
+		{0, 'Q', Themes[0].UsedKey},
+		{2, 'W', Themes[0].Correct},
+		{4, 'E', Themes[0].Partial},
+		{6, 'R', tcell.StyleDefault},
+	}
+	for _, e := range expected {
+		key, _, style, _ := s.GetContent(e.x, 0)
+		if key != e.key || style != e.style {
+			t.Fatalf("wrong key at %d. got=%c %v, expected=%c %v", e.x, key, style, e.key, e.style)
+		}
+	}
+}
+
+func TestLayoutKeysOnlyAlphabet(t *testing.T) {
+	l := layoutKeys(Keyboards[4], engine.NewSeenCharRecord(engine.AllRunes), 80)
+	if len(l.rows) != 3 || string(l.rows[0]) != "QWERTZUIOP" || string(l.rows[2]) != "YXCVBNM" {
+		t.Fatalf("keys outside of the alphabet were shown. got=%q", l.rows)
+	}
+}
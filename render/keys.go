@@ -0,0 +1,50 @@
+package render
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+)
+
+// drawKeys draws every key binding in a box over whatever is on the screen.
+func (r *Renderer) drawKeys(s tcell.Screen, km states.Keymap) {
+	width, height := s.Size()
+	lines := append(km.Help(), "", "any key to close")
+
+	boxW := 0
+	for _, line := range lines {
+		boxW = max(boxW, len([]rune(line)))
+	}
+	boxW += 4 // a border and a space on each side
+	boxH := len(lines) + 2
+	x1 := max((width-boxW)/2, 0)
+	y1 := max((height-boxH)/2, 0)
+	x2 := x1 + boxW - 1
+	y2 := y1 + boxH - 1
+
+	style := r.theme.Grid
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			s.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+	}
+	for x := x1 + 1; x < x2; x++ {
+		s.SetContent(x, y1, tcell.RuneHLine, nil, style)
+		s.SetContent(x, y2, tcell.RuneHLine, nil, style)
+	}
+	for y := y1 + 1; y < y2; y++ {
+		s.SetContent(x1, y, tcell.RuneVLine, nil, style)
+		s.SetContent(x2, y, tcell.RuneVLine, nil, style)
+	}
+	s.SetContent(x1, y1, tcell.RuneULCorner, nil, style)
+	s.SetContent(x2, y1, tcell.RuneURCorner, nil, style)
+	s.SetContent(x1, y2, tcell.RuneLLCorner, nil, style)
+	s.SetContent(x2, y2, tcell.RuneLRCorner, nil, style)
+
+	for i, line := range lines {
+		lineStyle := tcell.StyleDefault
+		if i == len(lines)-1 {
+			lineStyle = r.theme.Faded
+		}
+		drawTextWrapping(s, x1+2, y1+1+i, x2, lineStyle, line)
+	}
+}
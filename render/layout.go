@@ -0,0 +1,218 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+)
+
+// the line of the menu the first field is drawn on, counted in menu spacings
+const MENU_FIELDS_OFFSET int = 4
+
+// the spacing of the grid cells. compact grids have no lines between the cells
+const (
+	COMPACT_X_SPACING int = 2
+	COMPACT_Y_SPACING int = 1
+)
+
+// gameLayout is where everything of a game goes on the screen. Boards are tiled left to
+// right, wrapping onto new rows when the screen is too narrow, and each board is its grid
+// above a keyboard of the letters it has seen.
+type gameLayout struct {
+	xSpacing int
+	ySpacing int
+	compact  bool
+
+	// the guess rows shown. rows above firstRow are scrolled out of view
+	firstRow int
+	numRows  int
+
+	keys   keyLayout
+	gridW  int
+	gridH  int
+	boardW int
+	boardH int
+	cols   int
+	rows   int // rows of boards
+
+	x0      int // the top left corner of the first board
+	y0      int
+	statusY int
+	helpY   int
+
+	// the opponents of a versus round are drawn right of the boards when they fit
+	showOpponents bool
+	opponentsX    int
+}
+
+// layoutGame fits the game into width by height. Grids lose their lines and then scroll
+// their rows to fit. It reports false with the smallest size that would fit when even that
+// is not enough. The opponents of a versus round are left out before the boards are.
+func (r *Renderer) layoutGame(width, height int, gs *states.GameSession) (gameLayout, bool, int, int) {
+	if side := opponentsWidth(gs); side > 0 {
+		if l, ok, _, _ := r.layoutBoards(width-side, height, gs); ok {
+			boardsW := l.cols*(l.boardW+l.xSpacing) - l.xSpacing
+			l.x0 = (width - boardsW - side) / 2
+			l.opponentsX = l.x0 + boardsW
+			l.showOpponents = true
+			return l, true, 0, 0
+		}
+	}
+	return r.layoutBoards(width, height, gs)
+}
+
+// layoutBoards fits the boards of the game into width by height, see layoutGame.
+func (r *Renderer) layoutBoards(width, height int, gs *states.GameSession) (gameLayout, bool, int, int) {
+	hasStatus := gs.IsTimed() || gs.Parameters.IsRun()
+	help := wrapWords(gs.HelpText, width)
+	// a line is kept for the help text even without any, so the boards do not jump around
+	helpH := max(len(help), 1)
+	keyboard := Keyboards[gs.Parameters.Keyboard()]
+	seen := gs.Boards[0].SeenChars
+
+	spacings := [][2]int{{r.xSpacing, r.ySpacing}, {COMPACT_X_SPACING, COMPACT_Y_SPACING}}
+	for _, spacing := range spacings {
+		l := gameLayout{
+			xSpacing: spacing[0],
+			ySpacing: spacing[1],
+			compact:  spacing[1] == COMPACT_Y_SPACING,
+			keys:     layoutKeys(keyboard, seen, width),
+		}
+		l.gridW = l.gridWidth(gs.WordLen)
+		l.boardW = max(l.gridW, l.keys.width)
+		if l.boardW > width {
+			continue
+		}
+		l.cols = max(1, min(len(gs.Boards), (width+l.xSpacing)/(l.boardW+l.xSpacing)))
+		l.rows = (len(gs.Boards) + l.cols - 1) / l.cols
+
+		// the status is kept clear of the boards by a blank line
+		available := height - helpH
+		if hasStatus {
+			available -= 2
+		}
+		// only compact grids scroll, so roomier grids never hide a row
+		minRows := gs.NumGuesses
+		if l.compact {
+			minRows = 1
+		}
+		for n := gs.NumGuesses; n >= minRows; n-- {
+			if l.rows*l.boardHeight(n) > available {
+				continue
+			}
+			l.numRows = n
+			// the row being typed is always shown, along with as many before it as fit
+			l.firstRow = max(0, min(gs.GuessesUsed()+1, gs.NumGuesses)-n)
+			l.gridH = l.gridHeight(n)
+			l.boardH = l.boardHeight(n)
+
+			used := l.rows*l.boardH + helpH
+			top := 0
+			if hasStatus {
+				used += 2
+				top = 2
+			}
+			l.x0 = (width - (l.cols*(l.boardW+l.xSpacing) - l.xSpacing)) / 2
+			l.y0 = (height-used)/2 + top
+			l.statusY = l.y0 - 2
+			l.helpY = l.y0 + l.rows*l.boardH
+			return l, true, 0, 0
+		}
+	}
+
+	// the smallest fit is a compact grid showing a single row, with boards stacked if need be
+	compact := gameLayout{xSpacing: COMPACT_X_SPACING, ySpacing: COMPACT_Y_SPACING, compact: true}
+	longest := 0
+	for _, row := range keyboard.Rows {
+		longest = max(longest, len([]rune(row)))
+	}
+	needW := max(compact.gridWidth(gs.WordLen), longest)
+	atW := max(width, needW)
+	compact.keys = layoutKeys(keyboard, seen, atW)
+	boardW := max(compact.gridWidth(gs.WordLen), compact.keys.width)
+	cols := max(1, min(len(gs.Boards), (atW+compact.xSpacing)/(boardW+compact.xSpacing)))
+	needH := (len(gs.Boards)+cols-1)/cols*compact.boardHeight(1) + max(len(wrapWords(gs.HelpText, atW)), 1)
+	if hasStatus {
+		needH += 2
+	}
+	return gameLayout{}, false, needW, needH
+}
+
+func (l gameLayout) gridWidth(wordLen int) int {
+	if l.compact {
+		return wordLen*l.xSpacing - 1
+	}
+	return wordLen*l.xSpacing + 1
+}
+
+func (l gameLayout) gridHeight(numRows int) int {
+	if l.compact {
+		return numRows
+	}
+	return numRows*l.ySpacing + 1
+}
+
+// boardHeight is a board showing numRows rows with the blank line that follows it.
+func (l gameLayout) boardHeight(numRows int) int {
+	return l.gridHeight(numRows) + 1 + len(l.keys.rows) + 1
+}
+
+// board is the top left corner of the i-th board.
+func (l gameLayout) board(i int) (int, int) {
+	return l.x0 + (i%l.cols)*(l.boardW+l.xSpacing), l.y0 + (i/l.cols)*l.boardH
+}
+
+// keyboard is the top left corner of the keyboard below the i-th board.
+func (l gameLayout) keyboard(i int) (int, int) {
+	x1, y1 := l.board(i)
+	return x1 + (l.boardW-l.keys.width)/2, y1 + l.gridH + 1
+}
+
+// cell is where the letter of the grid at row, col is drawn relative to the grid's corner.
+func (l gameLayout) cell(row, col int) (int, int) {
+	if l.compact {
+		return col * l.xSpacing, row - l.firstRow
+	}
+	return col*l.xSpacing + l.xSpacing/2, (row-l.firstRow)*l.ySpacing + l.ySpacing/2
+}
+
+// drawCentered draws text centered on the screen from line y, wrapping between words when
+// it is too wide. It returns how many lines were drawn.
+func drawCentered(s tcell.Screen, y int, style tcell.Style, text string) int {
+	width, _ := s.Size()
+	lines := wrapWords(text, width)
+	for i, line := range lines {
+		drawTextWrapping(s, startingX(width, line), y+i, width, style, line)
+	}
+	return len(lines)
+}
+
+// wrapWords splits text into lines of at most width, breaking between words where it can.
+func wrapWords(text string, width int) []string {
+	if text == "" {
+		return nil
+	}
+	width = max(width, 1)
+	lines := []string{}
+	line := []rune{}
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		if len(line) > 0 && len(line)+1+len(w) > width {
+			lines = append(lines, string(line))
+			line = nil
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, w...)
+		for len(line) > width {
+			lines = append(lines, string(line[:width]))
+			line = line[width:]
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+func mockGameSession(numGuesses, numBoards int, guesses ...string) *states.GameSession {
+	wordRepo := utils.WordRepository{
+		// the guesses are never answers, so they can not end the game
+		Answers: map[string][]string{"5": {"tests", "slate"}},
+		Allowed: map[string][]string{"5": {"tests", "slate", "toast", "crane"}},
+	}
+	params, err := states.NewDefaultParameters(wordRepo)
+	if err != nil {
+		panic(err)
+	}
+	params.Fields[states.FIELD_NUM_GUESSES].Value = numGuesses
+	params.Fields[states.FIELD_BOARDS].Value = numBoards
+	gs, err := states.NewGameSession(params)
+	if err != nil {
+		panic(err)
+	}
+	for _, guess := range guesses {
+		if _, err := gs.Guess(guess); err != nil {
+			panic(err)
+		}
+	}
+	return gs
+}
+
+func TestLayoutGame(t *testing.T) {
+	testCases := []struct {
+		name          string
+		width, height int
+		gs            *states.GameSession
+		compact       bool
+		firstRow      int
+		numRows       int
+	}{
+		{"roomy", 80, 30, mockGameSession(6, 1), false, 0, 6},
+		{"compact", 80, 24, mockGameSession(12, 1), true, 0, 12},
+		{"scrolled", 40, 8, mockGameSession(20, 1, "toast", "crane"), true, 1, 2},
+	}
+
+	r := NewRenderer(Themes)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, ok, _, _ := r.layoutGame(tc.width, tc.height, tc.gs)
+			if !ok {
+				t.Fatal("the game did not fit")
+			}
+			if l.compact != tc.compact || l.firstRow != tc.firstRow || l.numRows != tc.numRows {
+				t.Fatalf("wrong layout. got compact=%v rows=%d+%d, expected compact=%v rows=%d+%d",
+					l.compact, l.firstRow, l.numRows, tc.compact, tc.firstRow, tc.numRows)
+			}
+			if bottom := l.helpY + 1; bottom > tc.height || l.x0 < 0 || l.y0 < 0 {
+				t.Fatalf("the layout does not fit. x0=%d, y0=%d, help at %d", l.x0, l.y0, l.helpY)
+			}
+		})
+	}
+}
+
+func TestLayoutGameTooSmall(t *testing.T) {
+	r := NewRenderer(Themes)
+	_, ok, needW, needH := r.layoutGame(8, 12, mockGameSession(6, 1))
+	if ok {
+		t.Fatal("the game fit a terminal too narrow for it")
+	}
+	// a row of the grid and the keyboard with the blank lines around them, and the help text
+	if needW != 10 || needH != 7 {
+		t.Fatalf("wrong size needed. got=%dx%d, expected=10x7", needW, needH)
+	}
+}
+
+func TestWrapWords(t *testing.T) {
+	testCases := []struct {
+		text     string
+		width    int
+		expected []string
+	}{
+		{"", 10, nil},
+		{"short", 10, []string{"short"}},
+		{"two words fit", 9, []string{"two words", "fit"}},
+		{"unbreakable", 4, []string{"unbr", "eaka", "ble"}},
+	}
+
+	for _, tc := range testCases {
+		if got := wrapWords(tc.text, tc.width); !slices.Equal(got, tc.expected) {
+			t.Fatalf("wrong lines for %q. got=%q, expected=%q", tc.text, got, tc.expected)
+		}
+	}
+}
+
+func TestLayoutGameOpponents(t *testing.T) {
+	testCases := []struct {
+		name          string
+		width, height int
+		shown         bool
+	}{
+		{"beside", 80, 30, true},
+		{"too narrow", 20, 30, false},
+	}
+
+	r := NewRenderer(Themes)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := mockGameSession(6, 1)
+			gs.Opponents = []*states.Opponent{{Name: "bob", Rows: [][]engine.CellState{{engine.CORRECT, engine.PARTIAL, engine.USED, engine.USED, engine.USED}}}}
+			s := tcell.NewSimulationScreen("")
+			s.Init()
+			s.SetSize(tc.width, tc.height)
+			r.DrawGameSession(s, gs)
+
+			l, ok, _, _ := r.layoutGame(tc.width, tc.height, gs)
+			if !ok || l.showOpponents != tc.shown {
+				t.Fatalf("wrong layout. got ok=%v opponents=%v, expected opponents=%v", ok, l.showOpponents, tc.shown)
+			}
+			if !tc.shown {
+				return
+			}
+			if right := l.opponentsX + opponentsWidth(gs); l.x0 < 0 || right > tc.width {
+				t.Fatalf("the opponents do not fit. x0=%d, right=%d", l.x0, right)
+			}
+			// the row shows only colors, never the letters
+			x := l.opponentsX + OPPONENT_X_SPACING + (opponentColWidth(5)-9)/2
+			for k, state := range gs.Opponents[0].Rows[0] {
+				drawn, _, style, _ := s.GetContent(x+k*COMPACT_X_SPACING, l.y0+1)
+				if drawn != '■' || style != r.theme.Cell(state) {
+					t.Fatalf("wrong cell %d. got=%c, expected=%c", k, drawn, '■')
+				}
+			}
+			if drawn, _, _, _ := s.GetContent(x, l.y0+2); drawn != '·' {
+				t.Fatalf("wrong empty row. got=%c, expected=%c", drawn, '·')
+			}
+		})
+	}
+}
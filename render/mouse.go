@@ -0,0 +1,41 @@
+package render
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+)
+
+// MenuFieldAt is the index of the menu field drawn on line y, or -1 when there is none.
+// Any column of the line counts so the narrow field names are easy to hit.
+func (r *Renderer) MenuFieldAt(s tcell.Screen, p *states.Parameters, y int) int {
+	_, height := s.Size()
+	ySpacing := r.menuSpacing(height, p)
+	if y%ySpacing != 0 {
+		return -1
+	}
+	idx := y/ySpacing - MENU_FIELDS_OFFSET
+	if idx < 0 || idx >= len(p.Fields) {
+		return -1
+	}
+	return idx
+}
+
+// KeyAt is the letter of the on-screen keyboard key drawn at x, y, under any of the boards.
+func (r *Renderer) KeyAt(s tcell.Screen, gs *states.GameSession, x, y int) (rune, bool) {
+	width, height := s.Size()
+	l, ok, _, _ := r.layoutGame(width, height, gs)
+	if !ok {
+		return 0, false
+	}
+	for i := range gs.Boards {
+		kx, ky := l.keyboard(i)
+		for row, keys := range l.keys.rows {
+			for col, key := range keys {
+				if dx, dy := l.keys.keyAt(row, col); kx+dx == x && ky+dy == y {
+					return key, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
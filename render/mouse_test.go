@@ -0,0 +1,82 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+func TestKeyAt(t *testing.T) {
+	testCases := []struct {
+		name          string
+		width, height int
+		numBoards     int
+	}{
+		{"roomy", 80, 30, 1},
+		{"compact", 40, 24, 2},
+	}
+
+	r := NewRenderer(Themes)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := tcell.NewSimulationScreen("")
+			s.Init()
+			s.SetSize(tc.width, tc.height)
+			gs := mockGameSession(6, tc.numBoards, "toast")
+			r.DrawGameSession(s, gs)
+
+			hits := 0
+			for y := 0; y < tc.height; y++ {
+				for x := 0; x < tc.width; x++ {
+					key, ok := r.KeyAt(s, gs, x, y)
+					if !ok {
+						continue
+					}
+					hits++
+					if drawn, _, _, _ := s.GetContent(x, y); drawn != key {
+						t.Fatalf("wrong key at %d,%d. got=%c, expected=%c", x, y, key, drawn)
+					}
+				}
+			}
+			if expected := 26 * tc.numBoards; hits != expected {
+				t.Fatalf("wrong number of keys. got=%d, expected=%d", hits, expected)
+			}
+		})
+	}
+}
+
+func TestMenuFieldAt(t *testing.T) {
+	for _, height := range []int{40, 24} {
+		s := tcell.NewSimulationScreen("")
+		s.Init()
+		s.SetSize(80, height)
+		r := NewRenderer(Themes)
+		p, err := states.NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests"}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.DrawMenu(s, p)
+
+		cells, width, _ := s.GetContents()
+		for y := 0; y < height; y++ {
+			line := []rune{}
+			for x := 0; x < width; x++ {
+				if runes := cells[y*width+x].Runes; len(runes) > 0 {
+					line = append(line, runes[0])
+				}
+			}
+			expected := -1
+			for i, field := range p.Fields {
+				if strings.HasPrefix(strings.TrimSpace(string(line)), field.Name+":") {
+					expected = i
+				}
+			}
+			if idx := r.MenuFieldAt(s, p, y); idx != expected {
+				t.Fatalf("wrong field at line %d of %d. got=%d, expected=%d", y, height, idx, expected)
+			}
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package render
 
 import (
-	"strconv"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
 	"gitlab.com/daneofmanythings/wohrdle/states"
 )
 
@@ -11,12 +14,26 @@ type Renderer struct {
 	xSpacing int
 	ySpacing int
 	defStyle tcell.Style
+
+	themes []Theme
+	theme  Theme
 }
 
-func NewRenderer() *Renderer {
+// NewRenderer draws with themes, picked by their index with UseTheme. The first one is used
+// until then.
+func NewRenderer(themes []Theme) *Renderer {
 	return &Renderer{
 		xSpacing: 4,
 		ySpacing: 2,
+		themes:   themes,
+		theme:    themes[0],
+	}
+}
+
+// UseTheme draws everything from now on with the theme at idx.
+func (r *Renderer) UseTheme(idx int) {
+	if idx >= 0 && idx < len(r.themes) {
+		r.theme = r.themes[idx]
 	}
 }
 
@@ -25,35 +42,73 @@ func (r *Renderer) DrawMenu(s tcell.Screen, p *states.Parameters) {
 	defer s.Show()
 
 	style := tcell.StyleDefault
-	style_faded := style.Foreground(tcell.ColorGrey)
-	width, _ := s.Size()
+	style_faded := r.theme.Faded
+	width, height := s.Size()
+
+	ySpacing := r.menuSpacing(height, p)
+	help_text_offset := MENU_FIELDS_OFFSET + len(p.Fields) + 1
 
 	welcome := "Welcome to WOHRDLE!"
 	instructions := "Please select word length and max guesses."
-	welX := startingX(width, welcome)
-	insX := startingX(width, instructions)
-	drawTextWrapping(s, welX, r.ySpacing, welX+len(welcome), style, welcome)
-	drawTextWrapping(s, insX, 2*r.ySpacing, insX+len(instructions), style, instructions)
-
-	starting_dynamic_offset := 4
+	drawCentered(s, ySpacing, style, welcome)
+	drawCentered(s, 2*ySpacing, style, instructions)
 
 	// dynamic portion ------
 	for i := range p.Fields {
 		title := p.Fields[i].Name
-		title = title + ": " + strconv.Itoa(p.Fields[i].Value)
+		title = title + ": " + p.FieldText(i)
 		x_start := startingX(width, title)
 		x_end := x_start + len(title)
-		drawTextWrapping(s, x_start, (starting_dynamic_offset+i)*r.ySpacing, x_end, determineMenuStyle(i, p), title)
+		drawTextWrapping(s, x_start, (MENU_FIELDS_OFFSET+i)*ySpacing, x_end, determineMenuStyle(i, p), title)
 	}
 	// -------
 
-	help_text_offset := starting_dynamic_offset + len(p.Fields) + 1
-	bindsMenu := "Navigate with arrow keys, 'wasd', or 'hjkl'. <return> to start."
-	bindsGame := "Type words. <esc> clears whole word. <ctrl-c> to go back."
-	menX := startingX(width, bindsMenu)
-	gamX := startingX(width, bindsGame)
-	drawTextWrapping(s, menX, help_text_offset*r.ySpacing, menX+len(bindsMenu), style_faded, bindsMenu)
-	drawTextWrapping(s, gamX, (help_text_offset+1)*r.ySpacing, gamX+len(bindsGame), style_faded, bindsGame)
+	if p.EnteringCode {
+		r.drawCodeEntry(s, help_text_offset*ySpacing, ySpacing, p)
+		return
+	}
+	km := p.Keymap
+	bindsMenu := km.Summary(states.CONTEXT_MENU, states.ACTION_NAV_UP, states.ACTION_NAV_DOWN, states.ACTION_NAV_LEFT,
+		states.ACTION_NAV_RIGHT, states.ACTION_START, states.ACTION_PUZZLE_CODE, states.ACTION_STATS, states.ACTION_KEYS)
+	bindsGame := "Type words. " + km.Summary(states.CONTEXT_GAME, states.ACTION_CLEAR_GUESS, states.ACTION_GIVE_UP,
+		states.ACTION_SUSPEND, states.ACTION_HINT_POSITION, states.ACTION_HINT_LETTER, states.ACTION_HINT_COUNT)
+	y := help_text_offset * ySpacing
+	y += max(drawCentered(s, y, style_faded, bindsMenu), ySpacing)
+	y += max(drawCentered(s, y, style_faded, bindsGame), ySpacing)
+
+	if p.HasSavedGame {
+		resume := km.Label(states.ACTION_RESUME) + " your last game."
+		drawCentered(s, y, style, resume)
+	}
+	if p.Message != "" {
+		drawCentered(s, y+ySpacing, r.theme.Loss, p.Message)
+	}
+	if p.ShowingKeys {
+		r.drawKeys(s, km)
+	}
+}
+
+// menuSpacing is the lines from one line of the menu to the next. The menu closes up its
+// lines when the terminal is too short for it.
+func (r *Renderer) menuSpacing(height int, p *states.Parameters) int {
+	if (MENU_FIELDS_OFFSET+len(p.Fields)+5)*r.ySpacing > height {
+		return COMPACT_Y_SPACING
+	}
+	return r.ySpacing
+}
+
+func (r *Renderer) drawCodeEntry(s tcell.Screen, y, ySpacing int, p *states.Parameters) {
+	style_faded := r.theme.Faded
+
+	prompt := "puzzle code: " + string(p.CodeInput) + "_"
+	drawCentered(s, y, tcell.StyleDefault.Reverse(true), prompt)
+
+	help := p.Keymap.Summary(states.CONTEXT_CODE, states.ACTION_PLAY_CODE, states.ACTION_CANCEL)
+	if p.CodeError != "" {
+		help = p.CodeError
+		style_faded = r.theme.Loss
+	}
+	drawCentered(s, y+ySpacing, style_faded, help)
 }
 
 func determineMenuStyle(curDisplayingIdx int, p *states.Parameters) tcell.Style {
@@ -64,34 +119,136 @@ func determineMenuStyle(curDisplayingIdx int, p *states.Parameters) tcell.Style
 }
 
 func startingX(width int, str string) int {
-	return (width - len(str)) / 2
+	return (width - len([]rune(str))) / 2
 }
 
 func (r *Renderer) DrawGameSession(s tcell.Screen, gs *states.GameSession) {
 	s.Clear()
-	style := tcell.StyleDefault
 	defer s.Show()
 
 	width, height := s.Size()
+	l, ok, needW, needH := r.layoutGame(width, height, gs)
+	if !ok {
+		r.drawTooSmall(s, needW, needH)
+		return
+	}
 
-	x1 := (width - (gs.WordLen+2)*r.xSpacing) / 2
-	y1 := (height - (gs.NumGuesses+1)*r.ySpacing) / 2
-	x2 := x1 + r.xSpacing*gs.WordLen
-	y2 := y1 + r.ySpacing*gs.NumGuesses
+	for i, b := range gs.Boards {
+		x1, y1 := l.board(i)
+		r.drawBoard(s, b, x1+(l.boardW-l.gridW)/2, y1, l, gs.WordLen)
+		kx, ky := l.keyboard(i)
+		r.drawKeyboard(s, kx, ky, l.keys, b.SeenChars)
+	}
+	if l.showOpponents {
+		r.drawOpponents(s, l, gs)
+	}
+
+	if gs.IsTimed() || gs.Parameters.IsRun() {
+		r.drawStatus(s, width/2, l.statusY, gs)
+	}
+
+	drawCentered(s, l.helpY, r.theme.Message(gs.GetState()), gs.HelpText)
+	if gs.ShowingKeys {
+		r.drawKeys(s, gs.Parameters.Keymap)
+	}
+}
+
+// drawTooSmall asks for a bigger terminal instead of drawing a game that does not fit.
+func (r *Renderer) drawTooSmall(s tcell.Screen, needW, needH int) {
+	width, height := s.Size()
+	text := fmt.Sprintf("terminal too small, need %dx%d", needW, needH)
+	drawCentered(s, (height-len(wrapWords(text, width)))/2, r.theme.Loss, text)
+}
+
+// drawStatus draws the clock of a timed game and the score of a run centered on x. The
+// last ten seconds are drawn like a loss.
+func (r *Renderer) drawStatus(s tcell.Screen, x, y int, gs *states.GameSession) {
+	style := tcell.StyleDefault.Bold(true)
+	parts := []string{}
+	if gs.IsTimed() {
+		seconds := int(gs.Remaining(time.Now()).Round(time.Second).Seconds())
+		if seconds <= 10 {
+			style = r.theme.Loss.Bold(true)
+		}
+		parts = append(parts, fmt.Sprintf("%d:%02d", seconds/60, seconds%60))
+	}
+	if gs.Parameters.IsRun() {
+		parts = append(parts, fmt.Sprintf("%d solved", len(gs.WordTimes)))
+	}
+	if gs.IsSurvival() {
+		parts = append(parts, fmt.Sprintf("%d guesses left", gs.GuessesLeft()), fmt.Sprintf("%d failed entries left", gs.MaxNumFails))
+	}
+	text := strings.Join(parts, " | ")
+	drawTextWrapping(s, x-len(text)/2, y, x+len(text), style, text)
+}
+
+// drawBoard draws the rows of a board that are in view with the grid's top left corner at
+// x1, y1. A solved board has its grid drawn in the solved style.
+func (r *Renderer) drawBoard(s tcell.Screen, b *engine.Board, x1, y1 int, l gameLayout, wordLen int) {
+	style := r.theme.Grid
+	if b.IsSolved() {
+		style = r.theme.Solved
+	}
+	if l.compact {
+		// without lines, empty cells are dotted so the length of the word still shows
+		for j := l.firstRow; j < l.firstRow+l.numRows; j++ {
+			for i := 0; i < wordLen; i++ {
+				x, y := l.cell(j, i)
+				s.SetContent(x1+x, y1+y, '·', nil, r.theme.Faded)
+			}
+		}
+	} else {
+		r.drawGridLines(s, x1, y1, l, wordLen, style)
+	}
+	// rows scrolled out of view are marked beside the grid
+	if l.firstRow > 0 {
+		s.SetContent(x1-2, y1, '↑', nil, r.theme.Faded)
+	}
+
+	// draw cell characters for the board grid
+	for j, row := range b.Grid {
+		if j < l.firstRow || j >= l.firstRow+l.numRows {
+			continue
+		}
+		for i, cell := range row {
+			x, y := l.cell(j, i)
+			r.drawCellChar(&cell, x1+x, y1+y, s)
+		}
+	}
+
+	// letters revealed by hints are shown faded until they are typed over
+	cur := b.RowsUsed()
+	if !b.IsSolved() && cur < len(b.Grid) && cur >= l.firstRow && cur < l.firstRow+l.numRows {
+		for i := len(b.Grid[cur]); i < wordLen; i++ {
+			if letter, ok := b.Revealed(i); ok {
+				x, y := l.cell(cur, i)
+				s.SetContent(x1+x, y1+y, letter, nil, r.theme.Faded)
+			}
+		}
+	}
+}
+
+// drawGridLines draws the lines around the cells of the rows in view.
+func (r *Renderer) drawGridLines(s tcell.Screen, x1, y1 int, l gameLayout, wordLen int, style tcell.Style) {
+	numRows := l.numRows
+	x2 := x1 + l.xSpacing*wordLen
+	y2 := y1 + l.ySpacing*numRows
 
 	// draw horizontal ticks
-	for y := 0; y <= gs.NumGuesses; y++ {
-		for x := 0; x < gs.WordLen; x++ {
-			for n := 1; n < r.xSpacing; n++ {
-				s.SetContent(x*r.xSpacing+x1+n, y*r.ySpacing+y1, tcell.RuneHLine, nil, style)
+	for y := 0; y <= numRows; y++ {
+		for x := 0; x < wordLen; x++ {
+			for n := 1; n < l.xSpacing; n++ {
+				s.SetContent(x*l.xSpacing+x1+n, y*l.ySpacing+y1, tcell.RuneHLine, nil, style)
 			}
 		}
 	}
 
 	// draw vertical ticks
-	for x := 0; x <= gs.WordLen; x++ {
-		for y := 0; y < gs.NumGuesses; y++ {
-			s.SetContent(x*r.xSpacing+x1, y*r.ySpacing+y1+1, tcell.RuneVLine, nil, style)
+	for x := 0; x <= wordLen; x++ {
+		for y := 0; y < numRows; y++ {
+			for n := 1; n < l.ySpacing; n++ {
+				s.SetContent(x*l.xSpacing+x1, y*l.ySpacing+y1+n, tcell.RuneVLine, nil, style)
+			}
 		}
 	}
 
@@ -103,94 +260,32 @@ func (r *Renderer) DrawGameSession(s tcell.Screen, gs *states.GameSession) {
 
 	// draw tees
 	// top
-	for i := 1; i < gs.WordLen; i++ {
-		s.SetContent(i*r.xSpacing+x1, y1, tcell.RuneTTee, nil, style)
+	for i := 1; i < wordLen; i++ {
+		s.SetContent(i*l.xSpacing+x1, y1, tcell.RuneTTee, nil, style)
 	}
 	// bottom
-	for i := 1; i < gs.WordLen; i++ {
-		s.SetContent(i*r.xSpacing+x1, y2, tcell.RuneBTee, nil, style)
+	for i := 1; i < wordLen; i++ {
+		s.SetContent(i*l.xSpacing+x1, y2, tcell.RuneBTee, nil, style)
 	}
 	// left
-	for i := 1; i < gs.NumGuesses; i++ {
-		s.SetContent(x1, i*r.ySpacing+y1, tcell.RuneLTee, nil, style)
+	for i := 1; i < numRows; i++ {
+		s.SetContent(x1, i*l.ySpacing+y1, tcell.RuneLTee, nil, style)
 	}
 	// Right
-	for i := 1; i < gs.NumGuesses; i++ {
-		s.SetContent(x2, i*r.ySpacing+y1, tcell.RuneRTee, nil, style)
+	for i := 1; i < numRows; i++ {
+		s.SetContent(x2, i*l.ySpacing+y1, tcell.RuneRTee, nil, style)
 	}
 
 	// fill middle with pluses
-	for j := 1; j < gs.NumGuesses; j++ {
-		for i := 1; i < gs.WordLen; i++ {
-			s.SetContent(i*r.xSpacing+x1, j*r.ySpacing+y1, tcell.RunePlus, nil, style)
-		}
-	}
-
-	// draw cell characters for session grid
-	for j, row := range gs.Grid {
-		for i, cell := range row {
-			drawCellChar(&cell, x1+i*r.xSpacing+r.xSpacing/2, y1+j*r.ySpacing+r.ySpacing/2, s)
+	for j := 1; j < numRows; j++ {
+		for i := 1; i < wordLen; i++ {
+			s.SetContent(i*l.xSpacing+x1, j*l.ySpacing+y1, tcell.RunePlus, nil, style)
 		}
 	}
+}
 
-	helpMessageX := (width - len(gs.HelpText)) / 2 // centering text
-	drawHelpMessage(helpMessageX, y2+r.ySpacing, s, gs)
-
-	drawSeenChars(x2+r.xSpacing, y1+r.ySpacing, x2+r.xSpacing+3, s, gs)
-}
-
-func drawCellChar(cell *states.Cell, x, y int, s tcell.Screen) {
-	var letterStyle tcell.Style
-	switch cell.GetState() {
-	case states.DEFAULT:
-		letterStyle = tcell.StyleDefault.Bold(true)
-	case states.CORRECT:
-		letterStyle = tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true)
-	case states.PARTIAL:
-		letterStyle = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorYellow).Bold(true)
-	default:
-		letterStyle = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset)
-	}
-
-	s.SetContent(x, y, cell.Char, nil, letterStyle)
-}
-
-func drawHelpMessage(x, y int, s tcell.Screen, gs *states.GameSession) {
-	var style tcell.Style
-	switch gs.GetState() {
-	case states.ACTIVE:
-		style = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorYellow)
-	case states.VICTORY:
-		style = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorGreen)
-	case states.LOSS:
-		style = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorRed)
-	}
-	drawTextWrapping(s, x, y, x+len(gs.HelpText), style, gs.HelpText)
-}
-
-func drawSeenChars(x, y, x2 int, s tcell.Screen, gs *states.GameSession) {
-	row := y
-	col := x
-	var style tcell.Style
-	for _, cell := range gs.SeenChars {
-		char := cell.Char
-		switch cell.GetState() {
-		case states.CORRECT:
-			style = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorGreen)
-		case states.DEFAULT:
-			style = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset)
-		case states.PARTIAL:
-			style = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorYellow)
-		case states.USED:
-			char = ' '
-		}
-		s.SetContent(col, row, char, nil, style)
-		col++
-		if col >= x2 {
-			row++
-			col = x
-		}
-	}
+func (r *Renderer) drawCellChar(cell *engine.Cell, x, y int, s tcell.Screen) {
+	s.SetContent(x, y, cell.Char, nil, r.theme.Cell(cell.GetState()))
 }
 
 func drawTextWrapping(s tcell.Screen, x1, y1, x2 int, style tcell.Style, text string) {
@@ -214,8 +309,9 @@ func CreateScreen() (tcell.Screen, error) {
 	if initErr := screen.Init(); initErr != nil {
 		return nil, initErr
 	}
-	screen.DisableMouse()
-	screen.DisablePaste()
+	// clicks and scrolls only. motion events would flood the loops for nothing
+	screen.EnableMouse(tcell.MouseButtonEvents)
+	screen.EnablePaste()
 
 	return screen, nil
 }
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+)
+
+const maxBarLen int = 30
+
+func (r *Renderer) DrawStats(s tcell.Screen, ss *states.StatsScreen) {
+	s.Clear()
+	defer s.Show()
+
+	style := tcell.StyleDefault
+	style_faded := r.theme.Faded
+	width, _ := s.Size()
+	summary := ss.Current()
+
+	row := 1
+	drawCentered := func(style tcell.Style, text string) {
+		x := startingX(width, text)
+		drawTextWrapping(s, x, row*r.ySpacing, x+len(text), style, text)
+		row++
+	}
+
+	drawCentered(style.Bold(true), "STATISTICS")
+	drawCentered(style.Reverse(true), "< "+summary.Title+" >")
+	drawCentered(style, fmt.Sprintf(
+		"played: %d | win: %d%% | streak: %d | max streak: %d",
+		summary.Played, summary.WinPercent(), summary.CurStreak, summary.MaxStreak,
+	))
+	if ss.Err != nil {
+		drawCentered(r.theme.Loss, "could not read all stats: "+ss.Err.Error())
+	}
+
+	// the histogram is drawn one line per guess count so it stays compact
+	drawCentered(style_faded, "guess distribution")
+	most := summary.Losses()
+	for _, count := range summary.Distribution {
+		most = max(most, count)
+	}
+	histX := (width - (maxBarLen + 8)) / 2
+	histY := row * r.ySpacing
+	for i, count := range summary.Distribution {
+		drawHistogramBar(s, histX, histY+i, fmt.Sprintf("%2d", i+1), count, most, r.theme.Victory)
+	}
+	drawHistogramBar(s, histX, histY+len(summary.Distribution), " X", summary.Losses(), most, r.theme.Loss)
+
+	help := ss.Keymap.Summary(states.CONTEXT_STATS)
+	helpY := histY + len(summary.Distribution) + r.ySpacing
+	helpX := startingX(width, help)
+	drawTextWrapping(s, helpX, helpY, helpX+len(help), style_faded, help)
+}
+
+func drawHistogramBar(s tcell.Screen, x, y int, label string, count, most int, style tcell.Style) {
+	barLen := 0
+	if most > 0 {
+		barLen = count * maxBarLen / most
+	}
+	line := fmt.Sprintf("%s %s %d", label, strings.Repeat("█", barLen), count)
+	drawTextWrapping(s, x, y, x+len([]rune(line)), style, line)
+}
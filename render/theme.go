@@ -0,0 +1,214 @@
+package render
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/config"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+// Theme is every style the renderer draws with.
+type Theme struct {
+	Name string
+
+	// the letters of the grid, by their score
+	Typed   tcell.Style
+	Correct tcell.Style
+	Partial tcell.Style
+	Used    tcell.Style
+	UsedKey tcell.Style // a key of the keyboard whose letter is not in the word
+
+	// the message below the grid, by the state of the game
+	Active  tcell.Style
+	Victory tcell.Style
+	Loss    tcell.Style
+
+	Grid   tcell.Style
+	Solved tcell.Style // the grid of a solved board
+	Faded  tcell.Style // help text and letters revealed by hints
+}
+
+// Themes are the built-in themes. The first one is the default.
+var Themes []Theme = []Theme{
+	{
+		Name:    "default",
+		Typed:   tcell.StyleDefault.Bold(true),
+		Correct: tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true),
+		Partial: tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true),
+		Used:    tcell.StyleDefault,
+		UsedKey: tcell.StyleDefault.Foreground(tcell.ColorGrey),
+		Active:  tcell.StyleDefault.Foreground(tcell.ColorYellow),
+		Victory: tcell.StyleDefault.Foreground(tcell.ColorGreen),
+		Loss:    tcell.StyleDefault.Foreground(tcell.ColorRed),
+		Grid:    tcell.StyleDefault,
+		Solved:  tcell.StyleDefault.Foreground(tcell.ColorGreen),
+		Faded:   tcell.StyleDefault.Foreground(tcell.ColorGrey),
+	},
+	{
+		// orange and blue tell apart for every common kind of color blindness
+		Name:    "colorblind",
+		Typed:   tcell.StyleDefault.Bold(true),
+		Correct: tcell.StyleDefault.Foreground(tcell.ColorOrange).Bold(true),
+		Partial: tcell.StyleDefault.Foreground(tcell.ColorDodgerBlue).Bold(true),
+		Used:    tcell.StyleDefault,
+		UsedKey: tcell.StyleDefault.Foreground(tcell.ColorGrey),
+		Active:  tcell.StyleDefault.Foreground(tcell.ColorDodgerBlue),
+		Victory: tcell.StyleDefault.Foreground(tcell.ColorOrange),
+		Loss:    tcell.StyleDefault.Bold(true).Underline(true),
+		Grid:    tcell.StyleDefault,
+		Solved:  tcell.StyleDefault.Foreground(tcell.ColorOrange),
+		Faded:   tcell.StyleDefault.Foreground(tcell.ColorGrey),
+	},
+	{
+		Name:    "high-contrast",
+		Typed:   tcell.StyleDefault.Foreground(tcell.ColorWhite).Bold(true),
+		Correct: tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorLime).Bold(true),
+		Partial: tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorYellow).Bold(true),
+		Used:    tcell.StyleDefault.Foreground(tcell.ColorSilver),
+		UsedKey: tcell.StyleDefault.Foreground(tcell.ColorGrey).StrikeThrough(true),
+		Active:  tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true),
+		Victory: tcell.StyleDefault.Foreground(tcell.ColorLime).Bold(true),
+		Loss:    tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true),
+		Grid:    tcell.StyleDefault.Foreground(tcell.ColorWhite),
+		Solved:  tcell.StyleDefault.Foreground(tcell.ColorLime).Bold(true),
+		Faded:   tcell.StyleDefault.Foreground(tcell.ColorSilver),
+	},
+	{
+		// no colors at all, for terminals without them
+		Name:    "monochrome",
+		Typed:   tcell.StyleDefault.Bold(true),
+		Correct: tcell.StyleDefault.Reverse(true).Bold(true),
+		Partial: tcell.StyleDefault.Underline(true).Bold(true),
+		Used:    tcell.StyleDefault,
+		UsedKey: tcell.StyleDefault.Dim(true).StrikeThrough(true),
+		Active:  tcell.StyleDefault,
+		Victory: tcell.StyleDefault.Bold(true),
+		Loss:    tcell.StyleDefault.Reverse(true),
+		Grid:    tcell.StyleDefault,
+		Solved:  tcell.StyleDefault.Bold(true),
+		Faded:   tcell.StyleDefault,
+	},
+}
+
+// Cell is the style of a letter of the grid.
+func (t Theme) Cell(state engine.CellState) tcell.Style {
+	switch state {
+	case engine.DEFAULT:
+		return t.Typed
+	case engine.CORRECT:
+		return t.Correct
+	case engine.PARTIAL:
+		return t.Partial
+	}
+	return t.Used
+}
+
+// Message is the style of the help text while the game is in state.
+func (t Theme) Message(state engine.GameState) tcell.Style {
+	switch state {
+	case engine.ACTIVE:
+		return t.Active
+	case engine.VICTORY:
+		return t.Victory
+	}
+	return t.Loss
+}
+
+// LoadThemes are the built-in themes followed by the user's. A user theme named like a
+// built-in one replaces it.
+func LoadThemes(custom []config.Theme) ([]Theme, error) {
+	themes := slices.Clone(Themes)
+	for _, c := range custom {
+		theme, err := newTheme(themes, c)
+		if err != nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(themes, func(t Theme) bool { return t.Name == theme.Name })
+		if idx == -1 {
+			themes = append(themes, theme)
+		} else {
+			themes[idx] = theme
+		}
+	}
+	return themes, nil
+}
+
+// ThemeNames are the names of themes, in order.
+func ThemeNames(themes []Theme) []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func newTheme(themes []Theme, c config.Theme) (Theme, error) {
+	if c.Name == "" {
+		return Theme{}, fmt.Errorf("a theme has no name")
+	}
+	base := c.Base
+	if base == "" {
+		base = Themes[0].Name
+	}
+	idx := slices.IndexFunc(themes, func(t Theme) bool { return t.Name == base })
+	if idx == -1 {
+		return Theme{}, fmt.Errorf("theme %q: no theme %q to start from", c.Name, base)
+	}
+
+	theme := themes[idx]
+	theme.Name = c.Name
+	styles := []struct {
+		style *tcell.Style
+		c     *config.Style
+	}{
+		{&theme.Typed, c.Typed},
+		{&theme.Correct, c.Correct},
+		{&theme.Partial, c.Partial},
+		{&theme.Used, c.Used},
+		{&theme.UsedKey, c.UsedKey},
+		{&theme.Active, c.Active},
+		{&theme.Victory, c.Victory},
+		{&theme.Loss, c.Loss},
+		{&theme.Grid, c.Grid},
+		{&theme.Solved, c.Solved},
+		{&theme.Faded, c.Faded},
+	}
+	for _, s := range styles {
+		if s.c == nil {
+			continue
+		}
+		style, err := newStyle(*s.c)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme %q: %w", c.Name, err)
+		}
+		*s.style = style
+	}
+	return theme, nil
+}
+
+func newStyle(c config.Style) (tcell.Style, error) {
+	fg, err := color(c.Fg)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	bg, err := color(c.Bg)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	return tcell.StyleDefault.Foreground(fg).Background(bg).Bold(c.Bold).Underline(c.Underline).Reverse(c.Reverse), nil
+}
+
+// color reads a color name or hex code. An empty one is the terminal's own color.
+func color(name string) (tcell.Color, error) {
+	if name == "" || strings.EqualFold(name, "default") {
+		return tcell.ColorDefault, nil
+	}
+	c := tcell.GetColor(name)
+	if c == tcell.ColorDefault {
+		return c, fmt.Errorf("unknown color %q", name)
+	}
+	return c, nil
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/config"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+func TestLoadThemes(t *testing.T) {
+	themes, err := LoadThemes([]config.Theme{
+		{Name: "solarized", Correct: &config.Style{Fg: "#859900", Bold: true}},
+		{Name: "monochrome", Base: "monochrome", Loss: &config.Style{Underline: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"default", "colorblind", "high-contrast", "monochrome", "solarized"}
+	if names := ThemeNames(themes); !slices.Equal(names, expected) {
+		t.Fatalf("wrong themes. got=%v, expected=%v", names, expected)
+	}
+	solarized := themes[4]
+	if solarized.Cell(engine.CORRECT) != tcell.StyleDefault.Foreground(tcell.NewHexColor(0x859900)).Bold(true) {
+		t.Fatalf("the style was not set. got=%v", solarized.Correct)
+	}
+	if solarized.Cell(engine.PARTIAL) != Themes[0].Partial {
+		t.Fatalf("a style that was not set did not come from the base theme. got=%v", solarized.Partial)
+	}
+	if themes[3].Message(engine.TIMEOUT) != tcell.StyleDefault.Underline(true) || themes[3].Correct != Themes[3].Correct {
+		t.Fatalf("the built-in theme was not replaced. got=%+v", themes[3])
+	}
+}
+
+func TestLoadThemesErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		theme config.Theme
+	}{
+		{"no name", config.Theme{}},
+		{"unknown base", config.Theme{Name: "x", Base: "nope"}},
+		{"unknown color", config.Theme{Name: "x", Used: &config.Style{Fg: "blurple"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := LoadThemes([]config.Theme{tc.theme}); err == nil {
+				t.Fatal("an invalid theme was accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/states"
+)
+
+// the columns of the opponents of a versus round. longer names are cut
+const (
+	OPPONENT_NAME_LEN  int = 8
+	OPPONENT_X_SPACING int = 3
+)
+
+// DrawVersus draws the round being played, or who is waiting for one before the first round.
+func (r *Renderer) DrawVersus(s tcell.Screen, v *states.Versus) {
+	if v.Game != nil {
+		r.DrawGameSession(s, v.Game)
+		return
+	}
+	s.Clear()
+	defer s.Show()
+
+	_, height := s.Size()
+	y := max(height/2-4, 0)
+	y += drawCentered(s, y, tcell.StyleDefault.Bold(true), "WOHRDLE VERSUS") + 1
+	if v.Name != "" {
+		y += drawCentered(s, y, tcell.StyleDefault, "playing as "+v.Name)
+		y += drawCentered(s, y, r.theme.Faded, "connected: "+strings.Join(v.Players, ", ")) + 1
+	}
+	style := r.theme.Active
+	if v.Disconnected {
+		style = r.theme.Loss
+	}
+	y += drawCentered(s, y, style, v.Message) + 1
+	drawCentered(s, y, r.theme.Faded, v.Parameters.Keymap.Summary(states.CONTEXT_GAME_OVER, states.ACTION_BACK))
+}
+
+// opponentColWidth is the width of the column of an opponent, their name or their rows.
+func opponentColWidth(wordLen int) int {
+	return max(wordLen*COMPACT_X_SPACING-1, OPPONENT_NAME_LEN)
+}
+
+// opponentsWidth is the room the opponents of a versus round take beside the boards.
+func opponentsWidth(gs *states.GameSession) int {
+	return len(gs.Opponents) * (opponentColWidth(gs.WordLen) + OPPONENT_X_SPACING)
+}
+
+// drawOpponents draws the name of every opponent above the colors of their rows. Only the
+// latest rows are shown when there is no room for all of them.
+func (r *Renderer) drawOpponents(s tcell.Screen, l gameLayout, gs *states.GameSession) {
+	colW := opponentColWidth(gs.WordLen)
+	gridW := gs.WordLen*COMPACT_X_SPACING - 1
+	numRows := min(gs.NumGuesses, max(l.rows*l.boardH-2, 1))
+	for i, opp := range gs.Opponents {
+		x := l.opponentsX + OPPONENT_X_SPACING + i*(colW+OPPONENT_X_SPACING)
+		name := []rune(opp.Name)
+		if len(name) > colW {
+			name = name[:colW]
+		}
+		drawTextWrapping(s, x+(colW-len(name))/2, l.y0, x+colW, r.opponentStyle(opp), string(name))
+
+		x1 := x + (colW-gridW)/2
+		firstRow := max(0, min(len(opp.Rows)+1, gs.NumGuesses)-numRows)
+		if firstRow > 0 {
+			s.SetContent(x1-2, l.y0+1, '↑', nil, r.theme.Faded)
+		}
+		for j := firstRow; j < firstRow+numRows; j++ {
+			y := l.y0 + 1 + j - firstRow
+			for k := 0; k < gs.WordLen; k++ {
+				if j < len(opp.Rows) && k < len(opp.Rows[j]) {
+					s.SetContent(x1+k*COMPACT_X_SPACING, y, '■', nil, r.theme.Cell(opp.Rows[j][k]))
+				} else {
+					s.SetContent(x1+k*COMPACT_X_SPACING, y, '·', nil, r.theme.Faded)
+				}
+			}
+		}
+	}
+}
+
+// opponentStyle is the style of the name of an opponent, by how their round went.
+func (r *Renderer) opponentStyle(opp *states.Opponent) tcell.Style {
+	switch {
+	case opp.Left:
+		return r.theme.Faded
+	case opp.State == engine.ACTIVE:
+		return tcell.StyleDefault.Bold(true)
+	}
+	return r.theme.Message(opp.State)
+}
@@ -0,0 +1,117 @@
+package save
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// NOTE: this is not encryption. The key ships with the binary, so it only keeps the target
+// from being read at a glance and catches hand edits of the save file.
+var key []byte = []byte("wohrdle save file")
+
+const version int = 1
+
+var (
+	ErrChecksum = errors.New("save file checksum mismatch")
+	ErrVersion  = errors.New("save file is from a different version")
+)
+
+type file struct {
+	Version  int    `json:"version"`
+	Data     string `json:"data"`
+	Checksum string `json:"checksum"`
+}
+
+// Write stores v at path, replacing whatever was there.
+func Write(path string, v any) error {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data := base64.StdEncoding.EncodeToString(obfuscate(plain))
+	b, err := json.MarshalIndent(file{
+		Version:  version,
+		Data:     data,
+		Checksum: checksum(data),
+	}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// written to the side and renamed so a crash never leaves half a save
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Read loads a save made by Write into v.
+func Read(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f := file{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	if f.Version != version {
+		return ErrVersion
+	}
+	if f.Checksum != checksum(f.Data) {
+		return ErrChecksum
+	}
+	obfuscated, err := base64.StdEncoding.DecodeString(f.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(obfuscate(obfuscated), v)
+}
+
+// Exists reports whether there is a save at path.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Remove deletes the save at path. A missing save is not an error.
+func Remove(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func checksum(data string) string {
+	h := sha256.New()
+	h.Write(key)
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// obfuscate xors b with a keystream. It is its own inverse.
+func obfuscate(b []byte) []byte {
+	out := make([]byte, len(b))
+	block := []byte{}
+	counter := make([]byte, 8)
+	for i := range b {
+		if i%sha256.Size == 0 {
+			binary.BigEndian.PutUint64(counter, uint64(i/sha256.Size))
+			sum := sha256.Sum256(append(append([]byte{}, key...), counter...))
+			block = sum[:]
+		}
+		out[i] = b[i] ^ block[i%sha256.Size]
+	}
+	return out
+}
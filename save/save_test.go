@@ -0,0 +1,65 @@
+package save
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type mockGame struct {
+	Target  string
+	Guesses []string
+}
+
+func TestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "save.json")
+	game := mockGame{Target: "VOLTS", Guesses: []string{"TOAST", "ADIEU"}}
+
+	if err := Write(path, game); err != nil {
+		t.Fatal(err)
+	}
+	if !Exists(path) {
+		t.Fatal("save was not written")
+	}
+
+	b, _ := os.ReadFile(path)
+	if strings.Contains(string(b), "VOLTS") || strings.Contains(string(b), "TOAST") {
+		t.Fatal("save file can be read as plain text")
+	}
+
+	loaded := mockGame{}
+	if err := Read(path, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Target != game.Target || len(loaded.Guesses) != 2 {
+		t.Fatalf("unexpected game. got=%+v, expected=%+v", loaded, game)
+	}
+
+	if err := Remove(path); err != nil || Exists(path) {
+		t.Fatalf("save was not removed. err=%v", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Fatalf("removing a missing save failed. err=%v", err)
+	}
+}
+
+func TestTamperedSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := Write(path, mockGame{Target: "VOLTS"}); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	f := string(b)
+	idx := strings.Index(f, `"data": "`) + len(`"data": "`)
+	replacement := "A"
+	if f[idx] == 'A' {
+		replacement = "B"
+	}
+	os.WriteFile(path, []byte(f[:idx]+replacement+f[idx+1:]), 0o644)
+
+	if err := Read(path, &mockGame{}); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("tampering was not detected. err=%v", err)
+	}
+}
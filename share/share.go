@@ -0,0 +1,92 @@
+package share
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+// the squares of the classic result grid
+const (
+	squareCorrect string = "🟩"
+	squarePartial string = "🟨"
+	squareUsed    string = "⬛"
+	squareEmpty   string = "  " // pads a board that was solved before the others
+)
+
+// Header describes the game above the grid.
+type Header struct {
+	Mode     string
+	WordLen  int
+	Used     int // guesses used
+	Allowed  int
+	Won      bool
+	HardMode bool
+}
+
+// String is like "Wohrdle classic 5 letters 4/6*". A loss shows X instead of the guesses
+// used and * marks hard-mode.
+func (h Header) String() string {
+	used := "X"
+	if h.Won {
+		used = fmt.Sprint(h.Used)
+	}
+	hard := ""
+	if h.HardMode {
+		hard = "*"
+	}
+	return fmt.Sprintf("Wohrdle %s %d letters %s/%d%s", h.Mode, h.WordLen, used, h.Allowed, hard)
+}
+
+// Text is the header and the grid of every board. Only the states of the cells are used,
+// so nothing gives the letters away.
+func Text(h Header, boards []*engine.Board) string {
+	return h.String() + "\n\n" + Grid(boards)
+}
+
+// Grid draws the scored rows of the boards side by side as colored squares.
+func Grid(boards []*engine.Board) string {
+	rows := 0
+	for _, b := range boards {
+		rows = max(rows, b.RowsUsed())
+	}
+
+	lines := []string{}
+	for i := 0; i < rows; i++ {
+		line := []string{}
+		for _, b := range boards {
+			line = append(line, squares(b, i))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(line, " "), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// squares is one row of a board. Rows past the ones it used are blank.
+func squares(b *engine.Board, row int) string {
+	if row >= b.RowsUsed() {
+		return strings.Repeat(squareEmpty, len([]rune(b.Target())))
+	}
+	var sb strings.Builder
+	for _, cell := range b.Grid[row] {
+		switch cell.GetState() {
+		case engine.CORRECT:
+			sb.WriteString(squareCorrect)
+		case engine.PARTIAL:
+			sb.WriteString(squarePartial)
+		default:
+			sb.WriteString(squareUsed)
+		}
+	}
+	return sb.String()
+}
+
+// WriteClipboard asks the terminal to copy text to the clipboard with an OSC 52 escape
+// sequence. Terminals without support ignore it.
+func WriteClipboard(w io.Writer, text string) error {
+	_, err := fmt.Fprintf(w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
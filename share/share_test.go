@@ -0,0 +1,83 @@
+package share
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+func play(targets []string, words []string, guesses ...string) *engine.GameSession {
+	gs, err := engine.NewGameSession(engine.Config{
+		WordLen:     len(targets[0]),
+		NumGuesses:  6,
+		MaxNumFails: 5,
+		NumBoards:   len(targets),
+		Words:       words,
+		Targets:     targets,
+	})
+	if err != nil {
+		panic(err)
+	}
+	for _, guess := range guesses {
+		if _, err := gs.Guess(guess); err != nil {
+			panic(err)
+		}
+	}
+	return gs
+}
+
+func TestHeader(t *testing.T) {
+	testCases := []struct {
+		header   Header
+		expected string
+	}{
+		{Header{Mode: "classic", WordLen: 5, Used: 4, Allowed: 6, Won: true}, "Wohrdle classic 5 letters 4/6"},
+		{Header{Mode: "daily", WordLen: 5, Used: 6, Allowed: 6, HardMode: true}, "Wohrdle daily 5 letters X/6*"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.header.String(); got != tc.expected {
+			t.Fatalf("wrong header. got=%q, expected=%q", got, tc.expected)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	gs := play([]string{"tests"}, []string{"tests", "toast"}, "toast", "tests")
+
+	expected := "🟩⬛⬛🟨🟨\n🟩🟩🟩🟩🟩"
+	if got := Grid(gs.Boards); got != expected {
+		t.Fatalf("wrong grid. got=%q, expected=%q", got, expected)
+	}
+	header := Header{Mode: "classic", WordLen: 5, Used: 2, Allowed: 6, Won: true}
+	if text := Text(header, gs.Boards); text != header.String()+"\n\n"+expected {
+		t.Fatalf("wrong text. got=%q", text)
+	}
+}
+
+func TestGridPadsSolvedBoards(t *testing.T) {
+	gs := play([]string{"tests", "toast"}, []string{"tests", "toast"}, "tests", "toast")
+
+	lines := strings.Split(Grid(gs.Boards), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrong number of rows. got=%d, expected=2", len(lines))
+	}
+	if lines[1] != strings.Repeat(squareEmpty, 5)+" 🟩🟩🟩🟩🟩" {
+		t.Fatalf("the solved board was not padded. got=%q", lines[1])
+	}
+}
+
+func TestWriteClipboard(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteClipboard(&buf, "🟩🟨⬛"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("🟩🟨⬛")) + "\a"
+	if buf.String() != expected {
+		t.Fatalf("wrong escape sequence. got=%q, expected=%q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,228 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+// maxWork bounds how many guesses are scored when ranking. Beyond it the candidates are
+// sampled, which keeps the first suggestion of a big word list under a second.
+const maxWork int = 2_000_000
+
+var (
+	ErrNoCandidates = errors.New("no words match the feedback")
+	ErrFeedback     = errors.New("feedback must be one of g (correct), y (present) or . (absent) per letter")
+)
+
+// Step is a guess and the feedback the game gave it.
+type Step struct {
+	Guess    string
+	Feedback []engine.CellState
+}
+
+// Suggestion is a ranked next guess.
+type Suggestion struct {
+	Word              string
+	Entropy           float64 // the expected information of the feedback, in bits
+	ExpectedRemaining float64 // the expected number of candidates left after guessing Word
+	Candidate         bool    // Word could be the answer
+}
+
+// Solver narrows down the answers using the same scoring as the game, so a word is only
+// ruled out when the game would have given different feedback for it.
+type Solver struct {
+	wordLen    int
+	folding    map[rune]rune
+	guesses    []string
+	candidates []string
+}
+
+// New makes a solver for words of wordLen letters. answers are the words the target can be
+// and allowed are the words that can be guessed. Words of other lengths are left out.
+// Letters are folded with folding like the game types them, so a word and its accented
+// spelling are the same word. folding can be nil.
+func New(wordLen int, answers, allowed []string, folding map[rune]rune) *Solver {
+	s := &Solver{
+		wordLen:    wordLen,
+		folding:    folding,
+		candidates: foldedSorted(wordLen, answers, folding),
+		guesses:    foldedSorted(wordLen, allowed, folding),
+	}
+	if len(s.guesses) == 0 {
+		s.guesses = slices.Clone(s.candidates)
+	}
+	return s
+}
+
+func foldedSorted(wordLen int, words []string, folding map[rune]rune) []string {
+	folded := []string{}
+	for _, word := range words {
+		if len([]rune(word)) == wordLen {
+			folded = append(folded, string(engine.FoldRunes(folding, []rune(strings.ToUpper(word)))))
+		}
+	}
+	slices.Sort(folded)
+	return slices.Compact(folded)
+}
+
+// Solve applies every step and ranks the best n next guesses.
+func Solve(wordLen int, answers, allowed []string, folding map[rune]rune, steps []Step, n int) ([]string, []Suggestion, error) {
+	s := New(wordLen, answers, allowed, folding)
+	for _, step := range steps {
+		if err := s.Apply(step.Guess, step.Feedback); err != nil {
+			return nil, nil, err
+		}
+	}
+	ranked, err := s.Rank(n)
+	return s.Candidates(), ranked, err
+}
+
+// Candidates are the upper-cased and folded answers still consistent with every step applied.
+func (s *Solver) Candidates() []string {
+	return slices.Clone(s.candidates)
+}
+
+// Apply keeps the candidates that would have scored guess exactly as feedback.
+func (s *Solver) Apply(guess string, feedback []engine.CellState) error {
+	guessAsRunes := engine.FoldRunes(s.folding, []rune(strings.ToUpper(guess)))
+	if len(guessAsRunes) != s.wordLen {
+		return fmt.Errorf("%s is not %d letters long", guess, s.wordLen)
+	}
+	if len(feedback) != s.wordLen {
+		return fmt.Errorf("the feedback has %d letters, expected %d", len(feedback), s.wordLen)
+	}
+
+	kept := []string{}
+	for _, candidate := range s.candidates {
+		if slices.Equal(engine.Score(guessAsRunes, []rune(candidate)), feedback) {
+			kept = append(kept, candidate)
+		}
+	}
+	if len(kept) == 0 {
+		return ErrNoCandidates
+	}
+	s.candidates = kept
+	return nil
+}
+
+// Rank returns the n guesses whose feedback says the most about the answer. Ties go to
+// words that could be the answer, then to alphabetical order. At least one is returned.
+func (s *Solver) Rank(n int) ([]Suggestion, error) {
+	if len(s.candidates) == 0 {
+		return nil, ErrNoCandidates
+	}
+	if len(s.candidates) == 1 {
+		return []Suggestion{{Word: s.candidates[0], ExpectedRemaining: 1, Candidate: true}}, nil
+	}
+
+	targets := sample(s.candidates, max(maxWork/max(len(s.guesses), 1), 1))
+	targetsAsRunes := make([][]rune, len(targets))
+	for i, target := range targets {
+		targetsAsRunes[i] = []rune(target)
+	}
+
+	suggestions := []Suggestion{}
+	for _, guess := range s.guesses {
+		guessAsRunes := []rune(guess)
+		buckets := map[int]int{}
+		for _, target := range targetsAsRunes {
+			buckets[patternKey(engine.Score(guessAsRunes, target))] += 1
+		}
+		_, isCandidate := slices.BinarySearch(s.candidates, guess)
+		suggestions = append(suggestions, newSuggestion(guess, buckets, len(targets), len(s.candidates), isCandidate))
+	}
+
+	slices.SortFunc(suggestions, func(a, b Suggestion) int {
+		switch {
+		case a.Entropy != b.Entropy:
+			if a.Entropy > b.Entropy {
+				return -1
+			}
+			return 1
+		case a.Candidate != b.Candidate:
+			if a.Candidate {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Word, b.Word)
+	})
+	return suggestions[:max(min(n, len(suggestions)), 1)], nil
+}
+
+// newSuggestion works out the entropy and expected remaining candidates from the bucket
+// sizes of the sampled targets, scaled back up to every candidate.
+func newSuggestion(word string, buckets map[int]int, sampled, numCandidates int, isCandidate bool) Suggestion {
+	sug := Suggestion{Word: word, Candidate: isCandidate}
+	for _, size := range buckets {
+		p := float64(size) / float64(sampled)
+		sug.Entropy -= p * math.Log2(p)
+		sug.ExpectedRemaining += p * p * float64(numCandidates)
+	}
+	// rounding keeps equally good guesses equal for the tie breaks
+	sug.Entropy = math.Round(sug.Entropy*1e9) / 1e9
+	return sug
+}
+
+// sample takes at most n evenly spread words.
+func sample(words []string, n int) []string {
+	if len(words) <= n {
+		return words
+	}
+	sampled := make([]string, n)
+	for i := range sampled {
+		sampled[i] = words[i*len(words)/n]
+	}
+	return sampled
+}
+
+func patternKey(pattern []engine.CellState) int {
+	key := 0
+	for _, state := range pattern {
+		key = key*4 + int(state)
+	}
+	return key
+}
+
+// ParseFeedback reads feedback like "gy..g": g for correct, y for present in the word and
+// . for absent. - _ x and b are also read as absent.
+func ParseFeedback(s string) ([]engine.CellState, error) {
+	feedback := []engine.CellState{}
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch r {
+		case 'g':
+			feedback = append(feedback, engine.CORRECT)
+		case 'y':
+			feedback = append(feedback, engine.PARTIAL)
+		case '.', '-', '_', 'x', 'b':
+			feedback = append(feedback, engine.USED)
+		default:
+			return nil, fmt.Errorf("%w. got %q", ErrFeedback, r)
+		}
+	}
+	if len(feedback) == 0 {
+		return nil, ErrFeedback
+	}
+	return feedback, nil
+}
+
+// FormatFeedback is the inverse of ParseFeedback.
+func FormatFeedback(feedback []engine.CellState) string {
+	var sb strings.Builder
+	for _, state := range feedback {
+		switch state {
+		case engine.CORRECT:
+			sb.WriteRune('g')
+		case engine.PARTIAL:
+			sb.WriteRune('y')
+		default:
+			sb.WriteRune('.')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package solver
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+var testWords = []string{"tests", "toast", "adieu", "volts", "lusts", "roast", "boast"}
+
+func TestParseFeedback(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []engine.CellState
+		err      bool
+	}{
+		{"gy..g", []engine.CellState{engine.CORRECT, engine.PARTIAL, engine.USED, engine.USED, engine.CORRECT}, false},
+		{" GYxb- ", []engine.CellState{engine.CORRECT, engine.PARTIAL, engine.USED, engine.USED, engine.USED}, false},
+		{"gz", nil, true},
+		{"", nil, true},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseFeedback(tc.input)
+		if (err != nil) != tc.err || !slices.Equal(got, tc.expected) {
+			t.Fatalf("unexpected feedback for %q. got=%v, err=%v, expected=%v", tc.input, got, err, tc.expected)
+		}
+		if err == nil && FormatFeedback(got) != map[string]string{"gy..g": "gy..g", " GYxb- ": "gy..."}[tc.input] {
+			t.Fatalf("unexpected format for %q. got=%s", tc.input, FormatFeedback(got))
+		}
+	}
+}
+
+// the solver has to agree with the game, so the feedback comes from a real session
+func TestAgreesWithGame(t *testing.T) {
+	for _, target := range testWords {
+		gs, err := engine.NewGameSession(engine.Config{
+			WordLen:     5,
+			NumGuesses:  6,
+			MaxNumFails: 5,
+			Words:       testWords,
+			Targets:     []string{target},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := New(5, testWords, testWords, nil)
+		for _, guess := range []string{"adieu", "tests", "toast"} {
+			res, err := gs.Guess(guess)
+			if err != nil {
+				break
+			}
+			feedback := []engine.CellState{}
+			for _, cell := range res.Rows[0] {
+				feedback = append(feedback, cell.GetState())
+			}
+			if err := s.Apply(guess, feedback); err != nil {
+				t.Fatalf("the solver ruled out %s. err=%v", target, err)
+			}
+			if !slices.Contains(s.Candidates(), gs.Target()) {
+				t.Fatalf("the solver ruled out %s after %s", target, guess)
+			}
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	feedback, _ := ParseFeedback(".g.yy")
+	candidates, ranked, err := Solve(5, testWords, testWords, nil, []Step{{"volts", feedback}}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(candidates, []string{"BOAST", "ROAST", "TOAST"}) {
+		t.Fatalf("unexpected candidates. got=%v", candidates)
+	}
+	// no word splits all three, so the candidates win the tie over TESTS
+	if len(ranked) != 3 || ranked[0].Word != "BOAST" || !ranked[0].Candidate || ranked[0].Entropy != ranked[2].Entropy {
+		t.Fatalf("unexpected ranking. got=%+v", ranked)
+	}
+
+	feedback, _ = ParseFeedback("ggggg")
+	if _, _, err := Solve(5, testWords, testWords, nil, []Step{{"zzzzz", feedback}}, 3); !errors.Is(err, ErrNoCandidates) {
+		t.Fatalf("impossible feedback was accepted. err=%v", err)
+	}
+	if _, _, err := Solve(5, testWords, testWords, nil, []Step{{"toasts", feedback}}, 3); err == nil {
+		t.Fatal("a guess of the wrong length was accepted")
+	}
+}
+
+func TestRankSingleCandidate(t *testing.T) {
+	feedback, _ := ParseFeedback("ggggg")
+	_, ranked, err := Solve(5, testWords, testWords, nil, []Step{{"adieu", feedback}}, 5)
+	if err != nil || len(ranked) != 1 || ranked[0].Word != "ADIEU" {
+		t.Fatalf("the answer was not suggested. got=%+v, err=%v", ranked, err)
+	}
+}
+
+func TestRankAtLeastOne(t *testing.T) {
+	s := New(5, testWords, testWords, nil)
+	for _, n := range []int{0, -1} {
+		ranked, err := s.Rank(n)
+		if err != nil || len(ranked) != 1 {
+			t.Fatalf("unexpected suggestions for n=%d. got=%d, expected=%d", n, len(ranked), 1)
+		}
+	}
+}
+
+func TestSolveFolding(t *testing.T) {
+	folding := map[rune]rune{'Ó': 'O'}
+	words := []string{"posta", "pósta", "torta"}
+	s := New(5, words, words, folding)
+	if !slices.Equal(s.Candidates(), []string{"POSTA", "TORTA"}) {
+		t.Fatalf("accented words were not folded. got=%v", s.Candidates())
+	}
+
+	// the game scores the typed POSTA as correct for PÓSTA
+	feedback, _ := ParseFeedback("ggggg")
+	if err := s.Apply("pósta", feedback); err != nil || !slices.Equal(s.Candidates(), []string{"POSTA"}) {
+		t.Fatalf("an accented guess was not folded. got=%v, err=%v", s.Candidates(), err)
+	}
+}
@@ -1,360 +1,266 @@
 package states
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
-	"slices"
 	"strings"
-	"unicode"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
-	"gitlab.com/daneofmanythings/wohrdle/utils"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
+	"gitlab.com/daneofmanythings/wohrdle/share"
+	"gitlab.com/daneofmanythings/wohrdle/stats"
 )
 
-type CellState int
-
-const (
-	DEFAULT CellState = iota
-	CORRECT
-	PARTIAL
-	USED
-)
-
-var cellStates []CellState = []CellState{DEFAULT, CORRECT, PARTIAL, USED}
-
-type Cell struct {
-	Char  rune
-	state CellState
-}
-
-func (c *Cell) isEqualTo(other Cell) bool {
-	return c.Char == other.Char && c.state == other.state
+// GameSession adapts the headless engine to tcell key events and help text.
+type GameSession struct {
+	*engine.GameSession
+	Parameters  Parameters
+	Puzzle      puzzle.Code // reproduces the current game
+	Started     time.Time   // when the current word was started
+	Suspended   bool        // the game was left to be resumed later
+	Sharing     bool        // the result was asked to be shared. the application loop shares it
+	ShowingKeys bool        // every key binding is shown over the game
+	pasting     bool        // keys are being pasted rather than typed
+	Opponents   []*Opponent // the other players of a versus round. see Versus
+
+	// a run plays word after word, see Parameters.IsRun
+	RunStarted time.Time
+	WordTimes  []time.Duration // how long each solved word took
+	stoppedAt  time.Time
+
+	HelpText string
 }
 
-func (c *Cell) SetState(state CellState) {
-	if !slices.Contains(cellStates, state) {
-		return
+func NewGameSession(params *Parameters) (*GameSession, error) {
+	code := params.NextPuzzle()
+	cfg, err := code.Config(params.Answers(), params.ValidWords())
+	if err != nil {
+		return nil, err
 	}
-	c.state = state
-}
-
-func (c *Cell) GetState() CellState {
-	return c.state
-}
-
-var AllRunes []rune = []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
-
-func NewSeenCharRecord() []Cell {
-	seenCharRecord := []Cell{}
-	for _, r := range AllRunes {
-		seenCharRecord = append(seenCharRecord, Cell{r, DEFAULT})
+	cfg.NumHints = params.NumHints()
+	cfg.Alphabet = params.WordRepo.Letters()
+	cfg.Folding = params.Folding()
+	session, err := engine.NewGameSession(cfg)
+	if err != nil {
+		return nil, err
 	}
-	return seenCharRecord
+	now := time.Now()
+	return &GameSession{
+		GameSession: session,
+		Parameters:  *params,
+		Puzzle:      code,
+		Started:     now,
+		RunStarted:  now,
+	}, nil
 }
 
-type GameState int
-
-const (
-	ACTIVE = iota
-	VICTORY
-	LOSS
-)
-
-var gameStates = []GameState{ACTIVE, VICTORY, LOSS}
-
-type GameSession struct {
-	Parameters Parameters
-
-	WordLen     int
-	NumGuesses  int
-	MaxNumFails int
-	HardMode    int
-
-	targetWordAsRunes  []rune
-	targetWordAsString string
-
-	Grid       [][]Cell
-	curIdx     int
-	SeenChars  []Cell
-	validWords []string
-	HelpText   string
-
-	state GameState
+// Record describes the game for the statistics once it has ended.
+func (gs *GameSession) Record() stats.Record {
+	finished := time.Now()
+	return stats.Record{
+		Finished:    finished,
+		Duration:    finished.Sub(gs.Started),
+		Puzzle:      gs.Puzzle.String(),
+		WordLen:     gs.WordLen,
+		NumGuesses:  gs.NumGuesses,
+		MaxNumFails: gs.Puzzle.MaxNumFails,
+		HardMode:    gs.HardMode,
+		Daily:       gs.Parameters.IsDaily(),
+		Absurd:      gs.Absurd,
+		Target:      strings.Join(gs.Targets(), ","),
+		Guesses:     gs.Guesses(),
+		FailsUsed:   gs.Puzzle.MaxNumFails - gs.MaxNumFails,
+		HintsUsed:   gs.HintsUsed(),
+		Mode:        gs.modeName(),
+		TimedOut:    gs.GetState() == engine.TIMEOUT,
+		Won:         gs.GetState() == engine.VICTORY,
+	}
 }
 
-func NewGameSession(params *Parameters) *GameSession {
-	gs := &GameSession{
-		Parameters:  *params,
-		WordLen:     params.Fields[0].Value,
-		NumGuesses:  params.Fields[1].Value,
-		MaxNumFails: params.Fields[2].Value,
-		HardMode:    params.Fields[3].Value,
-		curIdx:      0,
-		validWords:  params.ValidWords(),
-		state:       ACTIVE,
-		SeenChars:   NewSeenCharRecord(),
+// ShareText is the result of the game as colored squares, without any letters.
+func (gs *GameSession) ShareText() string {
+	mode := modeNames[gs.Parameters.Mode()]
+	if gs.Parameters.IsDaily() {
+		mode = "daily"
 	}
-	word := gs.validWords[rand.Intn(len(gs.validWords))]
-	// word := "volts"
-	gs.targetWordAsString = strings.ToUpper(word)
-	gs.targetWordAsRunes = utils.RuneSliceToUpper([]rune(word))
-
-	gs.Grid = make([][]Cell, gs.NumGuesses)
-	for i := range gs.Grid {
-		gs.Grid[i] = []Cell{}
+	if gs.Absurd {
+		mode += " absurd"
 	}
-
-	return gs
+	return share.Text(share.Header{
+		Mode:     mode,
+		WordLen:  gs.WordLen,
+		Used:     gs.GuessesUsed(),
+		Allowed:  gs.NumGuesses,
+		Won:      gs.GetState() == engine.VICTORY,
+		HardMode: gs.HardMode,
+	}, gs.Boards)
 }
 
-func (gs *GameSession) setState(state GameState) {
-	if !slices.Contains(gameStates, state) {
-		return
-	}
-	gs.state = state
+// Shared reports where the result went, keeping the keys of the game over screen in view.
+func (gs *GameSession) Shared(message string) {
+	gs.HelpText = message + " " + gs.gameOverPrompt()
 }
 
-func (gs *GameSession) GetState() GameState {
-	return gs.state
+// modeName is the mode for the statistics. classic games are left blank.
+func (gs *GameSession) modeName() string {
+	if gs.Parameters.Mode() == MODE_CLASSIC {
+		return ""
+	}
+	return modeNames[gs.Parameters.Mode()]
 }
 
 func (gs *GameSession) PushRune(r rune) {
-	if len(gs.Grid[gs.curIdx]) == gs.WordLen { // bounds checking
-		return
-	}
-	cell := Cell{
-		Char:  unicode.ToUpper(r),
-		state: DEFAULT,
-	}
-	gs.Grid[gs.curIdx] = append(gs.Grid[gs.curIdx], cell)
-
+	gs.GameSession.PushRune(r)
 	gs.HelpText = ""
 }
 
 func (gs *GameSession) PopRune() {
-	if len(gs.Grid[gs.curIdx]) == 0 { // bounds checking
-		return
-	}
-	gs.Grid[gs.curIdx] = gs.Grid[gs.curIdx][:len(gs.Grid[gs.curIdx])-1]
-
+	gs.GameSession.PopRune()
 	gs.HelpText = ""
 }
 
-// helper function for debugging
-func (gs *GameSession) getCurrentRow() []Cell {
-	return gs.Grid[gs.curIdx]
-}
-
 func (gs *GameSession) ClearCurrentGuess() {
-	gs.Grid[gs.curIdx] = nil
+	gs.GameSession.ClearCurrentGuess()
 	gs.HelpText = ""
 }
 
-func (gs *GameSession) UpdateGamestate() {
-	gs.HelpText = ""
-	failed_entry_loss := "Out of failed entries. %s was the word! [c]ontinue | go b[a]ck"
-	failed_entry := "%s not in word list. %d failed entries left"
-	victory := "%s is correct! [c]ontinue | go b[a]ck"
-	guess_loss := "%s was the word! [c]ontinue | go b[a]ck"
-	gave_up_loss := "Aborted. [c]ontinue | go b[a]ck"
-	hardmode_violated := "Hard-mode violated. %d failed entries left"
-
-	if gs.GetState() == LOSS {
-		gs.HelpText = fmt.Sprint(gave_up_loss)
-	}
-
-	if !gs.isValidWord() {
-		if len(gs.curGuessAsLowerString()) == gs.WordLen {
-			gs.MaxNumFails -= 1
-			if gs.MaxNumFails == 0 {
-				gs.setState(LOSS)
-				gs.HelpText = fmt.Sprintf(failed_entry_loss, gs.targetWordAsString)
-			} else {
-				gs.HelpText = fmt.Sprintf(failed_entry, gs.curGuessAsUpperString(), gs.MaxNumFails)
-			}
-		}
-		return
-	}
+func (gs *GameSession) GiveUp() {
+	gs.GameSession.GiveUp()
+	gs.stopClock(time.Now())
+	gs.HelpText = "Aborted. " + gs.gameOverPrompt()
+}
 
-	if gs.HardMode == 1 {
-		if !gs.isHardModeSatisfied() {
-			gs.MaxNumFails -= 1
-			if gs.MaxNumFails == 0 {
-				gs.setState(LOSS)
-				gs.HelpText = fmt.Sprintf(failed_entry_loss, gs.targetWordAsString)
-			} else {
-				gs.HelpText = fmt.Sprintf(hardmode_violated, gs.MaxNumFails)
-			}
-			return
-		}
+// gameOverPrompt lists the keys available once the game has ended.
+func (gs *GameSession) gameOverPrompt() string {
+	hints := ""
+	if gs.HintsUsed() > 0 {
+		hints = fmt.Sprintf("%d/%d hints used | ", gs.HintsUsed(), gs.HintsUsed()+gs.HintsLeft)
 	}
-
-	if gs.IsWinner() {
-		gs.setState(VICTORY)
-		gs.HelpText = fmt.Sprintf(victory, gs.curGuessAsUpperString())
-	}
-
-	gs.finalizeCurRow()
-
-	if gs.curIdx == gs.NumGuesses {
-		gs.setState(LOSS)
-		gs.HelpText = fmt.Sprintf(guess_loss, gs.targetWordAsString)
+	km := gs.Parameters.Keymap
+	if gs.Parameters.IsDaily() {
+		return hints + "Come back tomorrow! " + km.Summary(CONTEXT_GAME_OVER, ACTION_SHARE, ACTION_BACK) + " | code: " + gs.Puzzle.String()
 	}
+	return gs.survivalText() + hints + km.Summary(CONTEXT_GAME_OVER, ACTION_CONTINUE, ACTION_SHARE, ACTION_BACK) + " | code: " + gs.Puzzle.String()
 }
 
-func (gs *GameSession) curGuessAsLowerString() string {
-	var word string
-	for _, cell := range gs.Grid[gs.curIdx] {
-		word += utils.RuneToAlpha(unicode.ToLower(cell.Char))
+// TakeHint reveals a hint and reports it in the help text.
+func (gs *GameSession) TakeHint(kind engine.HintKind) {
+	hint, err := gs.Hint(kind)
+	switch {
+	case errors.Is(err, engine.ErrNoHints):
+		gs.HelpText = "No hints left."
+		return
+	case errors.Is(err, engine.ErrNothingToReveal):
+		gs.HelpText = "Nothing left to reveal."
+		return
+	case err != nil:
+		return
 	}
-	return word
-}
 
-func (gs *GameSession) curGuessAsUpperString() string {
-	var word string
-	for _, cell := range gs.Grid[gs.curIdx] {
-		word += utils.RuneToAlpha(unicode.ToUpper(cell.Char))
+	board := ""
+	if len(gs.Boards) > 1 {
+		board = fmt.Sprintf(" on board %d", hint.Board+1)
 	}
-	return word
-}
-
-func (gs *GameSession) isHardModeSatisfied() bool {
-	// Can't fail on the first guess
-	if gs.curIdx == 0 {
-		return true
+	switch hint.Kind {
+	case engine.HINT_POSITION:
+		gs.HelpText = fmt.Sprintf("Hint: letter %d is %c%s.", hint.Pos+1, hint.Letter, board)
+	case engine.HINT_LETTER:
+		gs.HelpText = fmt.Sprintf("Hint: %c is in the word%s.", hint.Letter, board)
+	case engine.HINT_COUNT:
+		gs.HelpText = fmt.Sprintf("Hint: %d words left%s.", hint.Count, board)
 	}
+	gs.HelpText += fmt.Sprintf(" %d hints left", gs.HintsLeft)
+}
 
-	// Need this to detect missed PARTIALS
-	countByRune := gs.countMapForCurrRow()
+func (gs *GameSession) UpdateGamestate() {
+	failed_entry_loss := "Out of failed entries. %s"
+	failed_entry := "%s not in word list. %d failed entries left"
+	victory := "%s is correct! "
+	guess_loss := "%s"
+	hardmode_violated := "Hard-mode violated. %d failed entries left"
 
-	// Making things easier to reason about in the code
-	prevRow := &gs.Grid[gs.curIdx-1]
-	currRow := &gs.Grid[gs.curIdx]
-	// First pass to see if any previously correct are missing and to update the countMap
-	// for the second pass
-	for i := range *prevRow {
-		// Making things easier to reason about in the code
-		prevRowCell := (*prevRow)[i]
-		currRowCell := (*currRow)[i]
-		if prevRowCell.GetState() != CORRECT {
-			continue
-		}
-		// Since the cell is correct, the chars should match
-		if prevRowCell.Char != currRowCell.Char {
-			return false
-		}
-		// they matched, so decrement the countMap
-		countByRune[currRowCell.Char] -= 1
+	guess := gs.CurrentGuess()
+	res, err := gs.Submit()
+	gs.stopClock(time.Now())
+	if res.State == engine.VICTORY && gs.Parameters.IsRun() {
+		gs.WordTimes = append(gs.WordTimes, gs.stoppedAt.Sub(gs.Started))
 	}
 
-	// Second pass to catch any missing PARTIALS. looking at the cells of the previous row
-	// in relation to how many are left in the countMap of the current row
-	for _, cell := range *prevRow {
-		// dont care if it isnt a PARTIAL
-		if cell.GetState() != PARTIAL {
-			continue
-		}
-		if countByRune[cell.Char] < 1 {
-			// We found a partial that isnt represented in the current row.
-			// IT HAS TO BE REPRESENTED
-			return false
-		}
-		// it is represented, so we decrement the count for that PARTIAL
-		countByRune[cell.Char] -= 1
+	gs.HelpText = ""
+	switch {
+	case errors.Is(err, engine.ErrWrongLength), errors.Is(err, engine.ErrGameOver):
+		// nothing to report
+	case err != nil && res.State == engine.LOSS:
+		gs.HelpText = fmt.Sprintf(failed_entry_loss, gs.targetsText()) + gs.gameOverPrompt()
+	case errors.Is(err, engine.ErrInvalidWord):
+		gs.HelpText = fmt.Sprintf(failed_entry, guess, res.FailsLeft)
+	case errors.Is(err, engine.ErrHardModeViolated):
+		gs.HelpText = fmt.Sprintf(hardmode_violated, res.FailsLeft)
+	case res.State == engine.VICTORY:
+		gs.HelpText = fmt.Sprintf(victory, guess) + gs.gameOverPrompt()
+	case res.State == engine.LOSS:
+		gs.HelpText = fmt.Sprintf(guess_loss, gs.targetsText()) + gs.gameOverPrompt()
 	}
-
-	return true
-}
-
-func (gs *GameSession) isValidWord() bool {
-	return slices.Contains(gs.validWords, gs.curGuessAsLowerString())
 }
 
-func (gs *GameSession) finalizeCurRow() {
-	// This populates the cells in the current row with thier correct stylings for the renderer
-
-	countByRune := gs.countMapForTargetWord() // This is to track repeat letters from ISSUE#1
-	// First pass
-	for i := range gs.Grid[gs.curIdx] {
-		cell := &gs.Grid[gs.curIdx][i]
-		idx := utils.Find[rune](AllRunes, cell.Char) // finding the location in the seen char tracker
-		if cell.Char == gs.targetWordAsRunes[i] {
-			countByRune[cell.Char] -= 1
-			cell.SetState(CORRECT)
-			gs.SeenChars[idx].SetState(CORRECT)
-		} else if slices.Contains(gs.targetWordAsRunes, cell.Char) {
-			cell.SetState(PARTIAL)
-			gs.SeenChars[idx].SetState(PARTIAL)
-		} else {
-			cell.SetState(USED)
-			gs.SeenChars[idx].SetState(USED)
-		}
-	}
-	// Second pass to remove potential false positives of PARTIALS when they have
-	// all been correctly guessed by comparing the remaining unfound CORRECTS
-	for i := range gs.Grid[gs.curIdx] {
-		cell := &gs.Grid[gs.curIdx][i]
-		idx := utils.Find[rune](AllRunes, cell.Char) // finding the location in the seen char tracker
-		if cell.GetState() != PARTIAL {
-			continue
+// targetsText lists the targets for the game over messages. Solved boards are left out
+// since their words are already on screen.
+func (gs *GameSession) targetsText() string {
+	unsolved := []string{}
+	for _, b := range gs.Boards {
+		if !b.IsSolved() {
+			unsolved = append(unsolved, b.Target())
 		}
-		// We know it is a PARTIAL
-		if countByRune[cell.Char] < 1 {
-			cell.SetState(USED)
-			gs.SeenChars[idx].SetState(CORRECT)
-		}
-		countByRune[cell.Char] -= 1
 	}
-	gs.curIdx += 1
-}
-
-func (gs *GameSession) countMapForTargetWord() map[rune]int {
-	countByRune := map[rune]int{}
-	for i := range gs.targetWordAsRunes {
-		countByRune[gs.targetWordAsRunes[i]] += 1
+	if len(unsolved) > 1 {
+		return strings.Join(unsolved, ", ") + " were the words! "
 	}
-	return countByRune
+	return strings.Join(unsolved, "") + " was the word! "
 }
 
-func (gs *GameSession) countMapForCurrRow() map[rune]int {
-	countByRune := map[rune]int{}
-	for i := range gs.curGuessAsUpperString() {
-		countByRune[rune(gs.curGuessAsUpperString()[i])] += 1
+// Reset starts a new game. A speedrun starts over. The game is left as it is when a new
+// one can not be started.
+func (gs *GameSession) Reset() error {
+	if err := gs.nextWord(); err != nil {
+		return err
 	}
-	return countByRune
+	gs.RunStarted = gs.Started
+	gs.WordTimes = nil
+	gs.HelpText = ""
+	return nil
 }
 
-func (gs *GameSession) IsWinner() bool {
-	if len(gs.targetWordAsRunes) != len(gs.Grid[gs.curIdx]) {
-		panic("len of word and guess do not match")
+// nextWord plays a new puzzle with the same settings.
+func (gs *GameSession) nextWord() error {
+	code := gs.Parameters.NextPuzzle()
+	cfg, err := code.Config(gs.Parameters.Answers(), gs.Parameters.ValidWords())
+	if err != nil {
+		return err
 	}
-	for i := range gs.targetWordAsRunes {
-		if gs.targetWordAsRunes[i] != gs.Grid[gs.curIdx][i].Char {
-			return false
-		}
+	if err := gs.GameSession.Reset(cfg.Targets); err != nil {
+		return err
 	}
-	return true
+	gs.Puzzle = code
+	gs.startClock()
+	return nil
 }
 
-func (gs *GameSession) Reset() {
-	gs.curIdx = 0
-	gs.setState(ACTIVE)
-	gs.MaxNumFails = gs.Parameters.Fields[2].Value // NOTE: update this if the menu position of max fails changes
-	for i := range gs.Grid {
-		gs.Grid[i] = nil
+func (gs *GameSession) HandleEventKey(ev *tcell.EventKey) bool {
+	gs.Tick(time.Now()) // a key pressed after the time ran out is too late
+	if gs.pasting {
+		// pasted text only ever types letters, so a stray newline cannot submit a guess
+		if gs.InAlphabet(ev.Rune()) {
+			gs.PushRune(ev.Rune())
+		}
+		return false
 	}
-	for i := range gs.SeenChars {
-		gs.SeenChars[i].SetState(DEFAULT)
+	if gs.ShowingKeys {
+		gs.ShowingKeys = false
+		return false
 	}
-
-	word := gs.validWords[rand.Intn(len(gs.validWords))]
-	gs.targetWordAsString = strings.ToUpper(word)
-	gs.targetWordAsRunes = utils.RuneSliceToUpper([]rune(word))
-	gs.HelpText = ""
-}
-
-func (gs *GameSession) HandleEventKey(ev *tcell.EventKey) bool {
-	if gs.state == ACTIVE {
+	if gs.GetState() == engine.ACTIVE {
 		if shouldExit := gs.activeEventKey(ev); shouldExit {
 			return true
 		}
@@ -366,29 +272,75 @@ func (gs *GameSession) HandleEventKey(ev *tcell.EventKey) bool {
 	return false
 }
 
+// HandleEventMouse acts on a click on the on-screen keyboard key of letter, or on nothing
+// when letter is 0. Clicking a key types its letter.
+func (gs *GameSession) HandleEventMouse(ev *tcell.EventMouse, letter rune) {
+	if ev.Buttons() != tcell.Button1 {
+		return
+	}
+	gs.Tick(time.Now())
+	if gs.ShowingKeys {
+		gs.ShowingKeys = false
+		return
+	}
+	if letter != 0 && gs.GetState() == engine.ACTIVE {
+		gs.PushRune(letter)
+	}
+}
+
+// HandleEventPaste starts or ends a paste, which types the letters pasted.
+func (gs *GameSession) HandleEventPaste(ev *tcell.EventPaste) {
+	gs.pasting = ev.Start()
+}
+
 func (gs *GameSession) gameOverEventKey(ev *tcell.EventKey) bool {
-	if ev.Rune() == 'c' || ev.Rune() == 'C' {
-		gs.Reset()
-		return false
-	} else if ev.Rune() == 'a' || ev.Rune() == 'A' || ev.Key() == tcell.KeyCtrlC {
+	switch gs.Parameters.Keymap.Action(CONTEXT_GAME_OVER, ev) {
+	case ACTION_SHARE:
+		gs.Sharing = true
+	case ACTION_CONTINUE:
+		if gs.canContinueRun() {
+			if err := gs.continueRun(); err != nil {
+				gs.HelpText = "Could not start the next word: " + err.Error() + ". " + gs.gameOverPrompt()
+			}
+		} else if !gs.Parameters.IsDaily() {
+			if err := gs.Reset(); err != nil {
+				gs.HelpText = "Could not start a new game: " + err.Error() + ". " + gs.gameOverPrompt()
+			}
+		}
+	case ACTION_BACK:
 		return true
+	case ACTION_KEYS:
+		gs.ShowingKeys = true
 	}
 	// fallthrough. nothing happens
 	return false
 }
 
 func (gs *GameSession) activeEventKey(ev *tcell.EventKey) bool {
-	if ev.Key() == tcell.KeyCtrlC {
-		gs.state = LOSS
-		gs.UpdateGamestate()
-	} else if ev.Key() == tcell.KeyEscape {
+	switch gs.Parameters.Keymap.Action(CONTEXT_GAME, ev) {
+	case ACTION_GIVE_UP:
+		gs.GiveUp()
+	case ACTION_SUSPEND:
+		gs.Suspended = true
+		return true
+	case ACTION_CLEAR_GUESS:
 		gs.ClearCurrentGuess()
-	} else if utils.RuneIsAlpha(ev.Rune()) {
-		gs.PushRune(ev.Rune())
-	} else if ev.Key() == tcell.KeyBackspace2 || ev.Key() == tcell.KeyBackspace {
+	case ACTION_HINT_POSITION:
+		gs.TakeHint(engine.HINT_POSITION)
+	case ACTION_HINT_LETTER:
+		gs.TakeHint(engine.HINT_LETTER)
+	case ACTION_HINT_COUNT:
+		gs.TakeHint(engine.HINT_COUNT)
+	case ACTION_BACKSPACE:
 		gs.PopRune()
-	} else if ev.Key() == tcell.KeyEnter {
+	case ACTION_SUBMIT:
 		gs.UpdateGamestate()
+	case ACTION_KEYS:
+		gs.ShowingKeys = true
+	default:
+		if gs.InAlphabet(ev.Rune()) {
+			gs.PushRune(ev.Rune())
+		}
 	}
 	// fallthrough. nothing happens
 	return false
@@ -0,0 +1,288 @@
+package states
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+// Action is what a key press asks for. Which actions a key can trigger depends on the screen.
+type Action int
+
+const (
+	ACTION_NONE Action = iota
+	ACTION_NAV_UP
+	ACTION_NAV_DOWN
+	ACTION_NAV_LEFT
+	ACTION_NAV_RIGHT
+	ACTION_START
+	ACTION_PUZZLE_CODE
+	ACTION_STATS
+	ACTION_RESUME
+	ACTION_QUIT
+	ACTION_SUBMIT
+	ACTION_BACKSPACE
+	ACTION_CLEAR_GUESS
+	ACTION_GIVE_UP
+	ACTION_SUSPEND
+	ACTION_HINT_POSITION
+	ACTION_HINT_LETTER
+	ACTION_HINT_COUNT
+	ACTION_SHARE
+	ACTION_CONTINUE
+	ACTION_BACK
+	ACTION_KEYS
+	ACTION_PLAY_CODE
+	ACTION_CANCEL
+)
+
+// actionInfo names an action for the config and describes it for the help.
+type actionInfo struct {
+	name        string
+	description string
+	defaults    []string
+}
+
+// NOTE: must be in the order of the actions
+var actionInfos = []actionInfo{
+	ACTION_NONE:          {},
+	ACTION_NAV_UP:        {"nav_up", "up", []string{"up", "k", "K", "w", "W"}},
+	ACTION_NAV_DOWN:      {"nav_down", "down", []string{"down", "j", "J", "s", "S"}},
+	ACTION_NAV_LEFT:      {"nav_left", "left", []string{"left", "h", "H", "a", "A"}},
+	ACTION_NAV_RIGHT:     {"nav_right", "right", []string{"right", "l", "L", "d", "D"}},
+	ACTION_START:         {"start", "start", []string{"enter"}},
+	ACTION_PUZZLE_CODE:   {"puzzle_code", "puzzle code", []string{"p", "P"}},
+	ACTION_STATS:         {"stats", "stats", []string{"t", "T"}},
+	ACTION_RESUME:        {"resume", "resume", []string{"r", "R"}},
+	ACTION_QUIT:          {"quit", "quit", []string{"ctrl-c"}},
+	ACTION_SUBMIT:        {"submit", "submit", []string{"enter"}},
+	ACTION_BACKSPACE:     {"backspace", "delete a letter", []string{"backspace"}},
+	ACTION_CLEAR_GUESS:   {"clear_guess", "clear the word", []string{"esc"}},
+	ACTION_GIVE_UP:       {"give_up", "give up", []string{"ctrl-c"}},
+	ACTION_SUSPEND:       {"suspend", "save for later", []string{"ctrl-z"}},
+	ACTION_HINT_POSITION: {"hint_position", "hint a letter's place", []string{"?"}},
+	ACTION_HINT_LETTER:   {"hint_letter", "hint a letter", []string{"!"}},
+	ACTION_HINT_COUNT:    {"hint_count", "hint the words left", []string{"#"}},
+	ACTION_SHARE:         {"share", "share", []string{"s", "S"}},
+	ACTION_CONTINUE:      {"continue", "continue", []string{"c", "C"}},
+	ACTION_BACK:          {"back", "back", []string{"b", "B", "esc", "ctrl-c"}},
+	ACTION_KEYS:          {"show_keys", "keys", []string{"f1", "ctrl-k"}},
+	ACTION_PLAY_CODE:     {"play_code", "play the puzzle", []string{"enter"}},
+	ACTION_CANCEL:        {"cancel", "cancel", []string{"esc", "ctrl-c"}},
+}
+
+// Context is a screen with its own set of actions. A key may only do one thing per screen.
+type Context int
+
+const (
+	CONTEXT_MENU Context = iota
+	CONTEXT_GAME
+	CONTEXT_GAME_OVER
+	CONTEXT_STATS
+	CONTEXT_CODE // entering a puzzle code in the menu
+)
+
+var contextNames = []string{"menu", "game", "game over", "stats", "puzzle code"}
+
+// the actions of each context, in the order they are listed in the help
+var contextActions = [][]Action{
+	CONTEXT_MENU:      {ACTION_NAV_UP, ACTION_NAV_DOWN, ACTION_NAV_LEFT, ACTION_NAV_RIGHT, ACTION_START, ACTION_PUZZLE_CODE, ACTION_STATS, ACTION_RESUME, ACTION_QUIT, ACTION_KEYS},
+	CONTEXT_GAME:      {ACTION_SUBMIT, ACTION_BACKSPACE, ACTION_CLEAR_GUESS, ACTION_GIVE_UP, ACTION_SUSPEND, ACTION_HINT_POSITION, ACTION_HINT_LETTER, ACTION_HINT_COUNT, ACTION_KEYS},
+	CONTEXT_GAME_OVER: {ACTION_CONTINUE, ACTION_SHARE, ACTION_BACK, ACTION_KEYS},
+	CONTEXT_STATS:     {ACTION_NAV_LEFT, ACTION_NAV_RIGHT, ACTION_STATS, ACTION_BACK},
+	CONTEXT_CODE:      {ACTION_PLAY_CODE, ACTION_BACKSPACE, ACTION_CANCEL},
+}
+
+// Key is a key press. Rune is only set for printable keys.
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+}
+
+// ParseKey reads a key like "k", "?", "enter", "esc", "up", "f1" or "ctrl-c".
+func ParseKey(s string) (Key, error) {
+	if r := []rune(s); len(r) == 1 {
+		return Key{Key: tcell.KeyRune, Rune: r[0]}, nil
+	}
+	switch name := strings.ToLower(s); name {
+	case "space":
+		return Key{Key: tcell.KeyRune, Rune: ' '}, nil
+	case "return":
+		return Key{Key: tcell.KeyEnter}, nil
+	case "escape":
+		return Key{Key: tcell.KeyEscape}, nil
+	case "backspace", "backspace2":
+		return Key{Key: tcell.KeyBackspace}, nil
+	default:
+		for k, n := range tcell.KeyNames {
+			if strings.ToLower(n) == name {
+				return Key{Key: k}, nil
+			}
+		}
+	}
+	return Key{}, fmt.Errorf("unknown key %q", s)
+}
+
+// String is the key as the help shows it.
+func (k Key) String() string {
+	if k.Key == tcell.KeyRune {
+		return string(k.Rune)
+	}
+	return tcell.KeyNames[k.Key]
+}
+
+// matches reports whether ev is a press of k. Both of the codes terminals send for
+// backspace are the same key.
+func (k Key) matches(ev *tcell.EventKey) bool {
+	if ev.Key() == tcell.KeyRune {
+		return k.Key == tcell.KeyRune && k.Rune == ev.Rune()
+	}
+	key := ev.Key()
+	if key == tcell.KeyBackspace2 {
+		key = tcell.KeyBackspace
+	}
+	return k.Key == key
+}
+
+// Keymap binds keys to actions.
+type Keymap map[Action][]Key
+
+// DefaultKeymap is the keymap without anything rebound.
+func DefaultKeymap() Keymap {
+	km := Keymap{}
+	for action, info := range actionInfos {
+		for _, s := range info.defaults {
+			key, err := ParseKey(s)
+			if err != nil {
+				panic(err)
+			}
+			km[Action(action)] = append(km[Action(action)], key)
+		}
+	}
+	return km
+}
+
+// LoadKeymap rebinds the defaults with keys from the config, by action name. The result is
+// checked for keys doing more than one thing on a screen.
+func LoadKeymap(rebound map[string][]string) (Keymap, error) {
+	km := DefaultKeymap()
+	errs := []error{}
+	names := []string{}
+	for name := range rebound {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		keys := rebound[name]
+		action := slices.IndexFunc(actionInfos, func(info actionInfo) bool { return info.name == name && name != "" })
+		if action == -1 {
+			errs = append(errs, fmt.Errorf("unknown action %q", name))
+			continue
+		}
+		km[Action(action)] = nil
+		for _, s := range keys {
+			key, err := ParseKey(s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			km[Action(action)] = append(km[Action(action)], key)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return km, km.Conflicts()
+}
+
+// Conflicts reports keys bound to more than one action of a screen, and letters bound in the
+// game or the puzzle code, where they are needed for typing.
+func (km Keymap) Conflicts() error {
+	errs := []error{}
+	for ctx, actions := range contextActions {
+		bound := map[Key]Action{}
+		for _, action := range actions {
+			for _, key := range km[action] {
+				if other, ok := bound[key]; ok && other != action {
+					errs = append(errs, fmt.Errorf("%s is bound to both %s and %s in the %s",
+						key, actionInfos[other].name, actionInfos[action].name, contextNames[ctx]))
+				}
+				bound[key] = action
+				if Context(ctx) == CONTEXT_GAME && key.Key == tcell.KeyRune && utils.RuneIsAlpha(key.Rune) {
+					errs = append(errs, fmt.Errorf("%s is bound to %s in the game, where it is typed", key, actionInfos[action].name))
+				}
+				if Context(ctx) == CONTEXT_CODE && key.Key == tcell.KeyRune && isCodeRune(key.Rune) {
+					errs = append(errs, fmt.Errorf("%s is bound to %s in the puzzle code, where it is typed", key, actionInfos[action].name))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Action is what ev asks for on the screen ctx, if anything.
+func (km Keymap) Action(ctx Context, ev *tcell.EventKey) Action {
+	for _, action := range contextActions[ctx] {
+		for _, key := range km[action] {
+			if key.matches(ev) {
+				return action
+			}
+		}
+	}
+	return ACTION_NONE
+}
+
+// Label is the first key of action with its description, like "[c]ontinue" when the key is
+// a letter of the description or "<Enter> start" when it is not.
+func (km Keymap) Label(action Action) string {
+	description := actionInfos[action].description
+	if len(km[action]) == 0 {
+		return description
+	}
+	key := km[action][0]
+	if key.Key == tcell.KeyRune {
+		idx := strings.IndexFunc(description, func(r rune) bool { return unicode.ToLower(r) == unicode.ToLower(key.Rune) })
+		if idx != -1 {
+			return description[:idx] + "[" + description[idx:idx+1] + "]" + description[idx+1:]
+		}
+	}
+	return "<" + key.String() + "> " + description
+}
+
+// Summary labels the actions of ctx, or just the ones given, on one line.
+func (km Keymap) Summary(ctx Context, actions ...Action) string {
+	if len(actions) == 0 {
+		actions = contextActions[ctx]
+	}
+	labels := []string{}
+	for _, action := range actions {
+		if len(km[action]) > 0 {
+			labels = append(labels, km.Label(action))
+		}
+	}
+	return strings.Join(labels, " | ")
+}
+
+// Help lists every key of every screen, for the keys overlay.
+func (km Keymap) Help() []string {
+	lines := []string{}
+	for ctx, actions := range contextActions {
+		if ctx > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, contextNames[ctx])
+		for _, action := range actions {
+			keys := []string{}
+			for _, key := range km[action] {
+				keys = append(keys, key.String())
+			}
+			lines = append(lines, fmt.Sprintf("  %-22s %s", actionInfos[action].description, strings.Join(keys, " ")))
+		}
+	}
+	return lines
+}
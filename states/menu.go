@@ -1,21 +1,73 @@
 package states
 
 import (
-	"os"
+	"fmt"
+	"math/rand"
 	"slices"
 	"strconv"
+	"time"
+	"unicode"
 
 	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
+	"gitlab.com/daneofmanythings/wohrdle/utils"
 )
 
 const (
 	MAX_GUESSES int = 20
 	MAX_FAILS   int = 20
+	MAX_BOARDS  int = 8
+	MAX_HINTS   int = 10
+
+	// time limits are in seconds
+	MIN_TIME_LIMIT  int = 30
+	MAX_TIME_LIMIT  int = 600
+	TIME_LIMIT_STEP int = 30
 
 	TRUE  int = 1
 	FALSE int = 0
 )
 
+// game modes. the names are what the menu shows
+const (
+	MODE_CLASSIC   int = iota
+	MODE_COUNTDOWN     // every word has to be solved within the time limit
+	MODE_SPEEDRUN      // solve as many words as possible within the time limit
+	MODE_SURVIVAL      // the guesses and failed entries left carry over from word to word
+)
+
+var modeNames = []string{"classic", "countdown", "speedrun", "survival"}
+
+// MenuAction is what the menu asks of the application loop after a key press.
+type MenuAction int
+
+const (
+	MENU_NONE MenuAction = iota
+	MENU_START
+	MENU_STATS
+	MENU_RESUME
+	MENU_QUIT
+)
+
+// the indexes of Parameters.Fields, in the order the menu shows them
+const (
+	FIELD_WORD_LEN    int = iota // word length
+	FIELD_NUM_GUESSES            // number of guesses
+	FIELD_NUM_FAILS              // failed word attempts
+	FIELD_HARD_MODE              // hard-mode flag
+	FIELD_DAILY                  // daily puzzle flag
+	FIELD_BOARDS                 // number of boards
+	FIELD_ABSURD                 // absurd flag
+	FIELD_HINTS                  // hint budget
+	FIELD_MODE                   // game mode
+	FIELD_TIME_LIMIT             // time limit in seconds, for the timed modes
+	FIELD_THEME                  // theme, an index into ThemeNames
+	FIELD_KEYBOARD               // keyboard layout, an index into KeyboardNames
+	FIELD_LANGUAGE               // language, an index into Languages
+	FIELD_FOLD                   // accent folding flag, accented letters are typed as plain ones
+)
+
 type Field struct {
 	Name  string
 	Value int
@@ -26,42 +78,305 @@ var defaultFields []Field = []Field{
 	{"num guesses", 6},
 	{"num failed words", 5},
 	{"hard-mode", 0},
+	{"daily", 0},
+	{"boards", 1},
+	{"absurd", 0},
+	{"hints", 0},
+	{"mode", MODE_CLASSIC},
+	{"time limit", 180},
+	{"theme", 0},
+	{"keyboard", 0},
+	{"language", 0},
+	{"fold accents", 1},
 }
 
+// Language is a word list to play with.
+type Language struct {
+	Code string // the ISO 639-1 code puzzle codes refer to it by. empty for a custom list
+	Name string // what the menu and the config call it
+	Repo utils.WordRepository
+}
+
+// CUSTOM_LANGUAGE is the name of a word list given by the player.
+const CUSTOM_LANGUAGE string = "custom"
+
 type Parameters struct {
-	// Field[0] >> word length
-	// Field[1] >> number of guesses
-	// Field[2] >> failed word attempts
+	// indexed by the FIELD_ constants
 	Fields        []Field
 	CurEditingIdx int
 
-	WordRepo   map[string][]string
+	ThemeNames    []string
+	KeyboardNames []string
+
+	// the word list of the selected language. see useLanguage
+	Languages  []Language
+	WordRepo   utils.WordRepository
 	MinWordLen int
 	MaxWordLen int
+
+	// puzzle code entry from the menu
+	EnteringCode bool
+	CodeInput    []rune
+	CodeError    string
+
+	HasSavedGame bool
+	Message      string // reported below the menu, e.g. when a saved game could not be resumed
+
+	Keymap      Keymap
+	ShowingKeys bool // every key binding is shown over the menu
+	pasting     bool // keys are being pasted rather than typed
+
+	pendingSeed *uint32
 }
 
-func NewDefaultParameters(wordRepo map[string][]string) *Parameters {
-	// finding the bounds of wordLen for menu wrapping
+// NewDefaultParameters is the menu for playing with a single word list.
+func NewDefaultParameters(wordRepo utils.WordRepository) (*Parameters, error) {
+	return NewParameters([]Language{{Name: CUSTOM_LANGUAGE, Repo: wordRepo}})
+}
+
+// NewParameters is the menu for playing in any of languages. The first one is selected.
+// Every language must have answers, so switching between them later can not fail.
+func NewParameters(languages []Language) (*Parameters, error) {
+	p := &Parameters{
+		Fields:        slices.Clone(defaultFields),
+		CurEditingIdx: 0,
+		Languages:     languages,
+		ThemeNames:    []string{"default"},
+		KeyboardNames: []string{"qwerty"},
+		Keymap:        DefaultKeymap(),
+	}
+	for i := range languages {
+		p.Fields[FIELD_LANGUAGE].Value = i
+		if err := p.useLanguage(); err != nil {
+			return nil, err
+		}
+	}
+	p.Fields = slices.Clone(defaultFields)
+	return p, p.useLanguage()
+}
+
+// useLanguage switches to the word list of the selected language. The word length is kept
+// when the list has words of it.
+func (p *Parameters) useLanguage() error {
+	language := p.Languages[p.Language()]
+
+	// finding the bounds of wordLen for menu wrapping. only lengths with answers can be played
 	word_lengths := []int{}
-	for str_len := range wordRepo {
+	for str_len, answers := range language.Repo.Answers {
+		if len(answers) == 0 {
+			continue
+		}
 		word_len, err := strconv.Atoi(str_len)
 		if err != nil {
 			panic("WHY ARE WE PANICKING HERE. SOMETHING HAS GONE TERRIBLY WRONG")
 		}
 		word_lengths = append(word_lengths, word_len)
 	}
+	if len(word_lengths) == 0 {
+		return fmt.Errorf("%s: no answers to play", language.Name)
+	}
+	p.WordRepo = language.Repo
+	p.MinWordLen = slices.Min(word_lengths)
+	p.MaxWordLen = slices.Max(word_lengths)
 
-	return &Parameters{
-		Fields:        defaultFields,
-		CurEditingIdx: 0,
-		WordRepo:      wordRepo,
-		MinWordLen:    slices.Min(word_lengths),
-		MaxWordLen:    slices.Max(word_lengths),
+	// word lists might not have the current length, or even the default one
+	if len(p.Answers()) == 0 {
+		p.Fields[FIELD_WORD_LEN].Value = defaultFields[FIELD_WORD_LEN].Value
+	}
+	if len(p.Answers()) == 0 {
+		p.Fields[FIELD_WORD_LEN].Value = p.MinWordLen
+	}
+	return nil
+}
+
+// stepWordLen moves the word length by dir, wrapping around and skipping lengths without answers.
+func (p *Parameters) stepWordLen(dir int) {
+	val := &p.Fields[FIELD_WORD_LEN].Value
+	for {
+		*val += dir
+		if *val > p.MaxWordLen {
+			*val = p.MinWordLen
+		} else if *val < p.MinWordLen {
+			*val = p.MaxWordLen
+		}
+		if len(p.Answers()) > 0 {
+			return
+		}
 	}
 }
 
+// ValidWords are the allowed guesses for the selected word length.
 func (p *Parameters) ValidWords() []string {
-	return p.WordRepo[strconv.Itoa(p.Fields[0].Value)]
+	return p.WordRepo.Allowed[strconv.Itoa(p.Fields[FIELD_WORD_LEN].Value)]
+}
+
+// Answers are the words a target can be drawn from for the selected word length.
+func (p *Parameters) Answers() []string {
+	return p.WordRepo.Answers[strconv.Itoa(p.Fields[FIELD_WORD_LEN].Value)]
+}
+
+// IsDaily reports whether the word of the day is played. A run needs more than one word,
+// so it never is.
+func (p *Parameters) IsDaily() bool {
+	return p.Fields[FIELD_DAILY].Value == TRUE && !p.IsRun()
+}
+
+func (p *Parameters) IsAbsurd() bool {
+	return p.Fields[FIELD_ABSURD].Value == TRUE
+}
+
+func (p *Parameters) Mode() int {
+	return p.Fields[FIELD_MODE].Value
+}
+
+// IsRun reports whether the mode plays word after word, keeping score.
+func (p *Parameters) IsRun() bool {
+	return p.Mode() == MODE_SPEEDRUN || p.Mode() == MODE_SURVIVAL
+}
+
+// IsTimed reports whether the mode plays against the clock.
+func (p *Parameters) IsTimed() bool {
+	return p.Mode() == MODE_COUNTDOWN || p.Mode() == MODE_SPEEDRUN
+}
+
+func (p *Parameters) TimeLimit() time.Duration {
+	return time.Duration(p.Fields[FIELD_TIME_LIMIT].Value) * time.Second
+}
+
+// Theme is the index of the theme in ThemeNames.
+func (p *Parameters) Theme() int {
+	if p.Fields[FIELD_THEME].Value >= len(p.ThemeNames) {
+		return 0
+	}
+	return p.Fields[FIELD_THEME].Value
+}
+
+// Keyboard is the index of the keyboard layout in KeyboardNames.
+func (p *Parameters) Keyboard() int {
+	if p.Fields[FIELD_KEYBOARD].Value >= len(p.KeyboardNames) {
+		return 0
+	}
+	return p.Fields[FIELD_KEYBOARD].Value
+}
+
+// Language is the index of the language in Languages.
+func (p *Parameters) Language() int {
+	if p.Fields[FIELD_LANGUAGE].Value >= len(p.Languages) {
+		return 0
+	}
+	return p.Fields[FIELD_LANGUAGE].Value
+}
+
+// setLanguage selects the language at idx and switches to its word list.
+func (p *Parameters) setLanguage(idx int) {
+	p.Fields[FIELD_LANGUAGE].Value = idx
+	if err := p.useLanguage(); err != nil {
+		panic(err) // NewParameters only accepts languages with answers
+	}
+}
+
+// Folding is the accent folding table of the language, or nil when accents are typed as they are.
+func (p *Parameters) Folding() map[rune]rune {
+	if p.Fields[FIELD_FOLD].Value == FALSE {
+		return nil
+	}
+	return p.WordRepo.FoldingTable()
+}
+
+// FieldText is how the value of a field is shown in the menu.
+// NOTE: This must be updated when a menu item without a plain number is added
+func (p *Parameters) FieldText(idx int) string {
+	val := p.Fields[idx].Value
+	switch idx {
+	case FIELD_MODE:
+		return modeNames[val]
+	case FIELD_TIME_LIMIT:
+		return fmt.Sprintf("%d:%02d", val/60, val%60)
+	case FIELD_THEME:
+		return p.ThemeNames[p.Theme()]
+	case FIELD_KEYBOARD:
+		return p.KeyboardNames[p.Keyboard()]
+	case FIELD_LANGUAGE:
+		return p.Languages[p.Language()].Name
+	case FIELD_FOLD:
+		if len(p.WordRepo.FoldingTable()) == 0 {
+			return "none" // the language has no accents to fold
+		}
+	}
+	return strconv.Itoa(val)
+}
+
+// NumHints is the hint budget. It is not part of puzzle codes since it never changes the target.
+func (p *Parameters) NumHints() int {
+	return p.Fields[FIELD_HINTS].Value
+}
+
+// NextPuzzle is the puzzle for a new game. A code entered from the menu or command line
+// is only played once, after which seeds are random again.
+func (p *Parameters) NextPuzzle() puzzle.Code {
+	code := puzzle.Code{
+		WordLen:     p.Fields[FIELD_WORD_LEN].Value,
+		NumGuesses:  p.Fields[FIELD_NUM_GUESSES].Value,
+		MaxNumFails: p.Fields[FIELD_NUM_FAILS].Value,
+		HardMode:    p.Fields[FIELD_HARD_MODE].Value == TRUE,
+		NumBoards:   p.Fields[FIELD_BOARDS].Value,
+		Absurd:      p.IsAbsurd(),
+		Language:    p.Languages[p.Language()].Code,
+	}
+	if code.Absurd {
+		code.NumBoards = 1 // the adversary only ever plays a single board
+	}
+	switch {
+	case p.pendingSeed != nil:
+		code.Seed = *p.pendingSeed
+		p.pendingSeed = nil
+	case p.IsDaily():
+		code.Seed = engine.DailySeed(time.Now().UTC(), code.WordLen)
+	default:
+		code.Seed = rand.Uint32()
+	}
+	return code
+}
+
+// ApplyPuzzleCode sets the menu to the settings of a puzzle code so the next game plays it.
+func (p *Parameters) ApplyPuzzleCode(code puzzle.Code) error {
+	// codes without a language are played with the selected one
+	lang := p.Language()
+	if code.Language != "" {
+		lang = slices.IndexFunc(p.Languages, func(l Language) bool { return l.Code == code.Language })
+		if lang == -1 {
+			return fmt.Errorf("no word list for language %q", code.Language)
+		}
+	}
+	if len(p.Languages[lang].Repo.Answers[strconv.Itoa(code.WordLen)]) == 0 {
+		return fmt.Errorf("no words of length %d", code.WordLen)
+	}
+	if code.NumGuesses > MAX_GUESSES {
+		return fmt.Errorf("num guesses must be at most %d", MAX_GUESSES)
+	}
+	if code.MaxNumFails > MAX_FAILS {
+		return fmt.Errorf("num failed words must be at most %d", MAX_FAILS)
+	}
+	if code.NumBoards > MAX_BOARDS {
+		return fmt.Errorf("boards must be at most %d", MAX_BOARDS)
+	}
+
+	p.setLanguage(lang)
+	p.Fields[FIELD_WORD_LEN].Value = code.WordLen
+	p.Fields[FIELD_NUM_GUESSES].Value = code.NumGuesses
+	p.Fields[FIELD_NUM_FAILS].Value = code.MaxNumFails
+	p.Fields[FIELD_HARD_MODE].Value = FALSE
+	if code.HardMode {
+		p.Fields[FIELD_HARD_MODE].Value = TRUE
+	}
+	p.Fields[FIELD_DAILY].Value = FALSE
+	p.Fields[FIELD_BOARDS].Value = code.NumBoards
+	p.Fields[FIELD_ABSURD].Value = FALSE
+	if code.Absurd {
+		p.Fields[FIELD_ABSURD].Value = TRUE
+	}
+	p.pendingSeed = &code.Seed
+	return nil
 }
 
 func (p *Parameters) IncCurField() {
@@ -74,30 +389,79 @@ func (p *Parameters) IncCurField() {
 
 // NOTE: This must be updated when menu items are added
 func (p *Parameters) IncValAtCurField() {
+	p.pendingSeed = nil // a changed setting is no longer the entered puzzle
 	switch p.CurEditingIdx {
-	case 0: // word length
-		val := &p.Fields[0].Value
-		if *val == p.MaxWordLen {
+	case FIELD_WORD_LEN:
+		p.stepWordLen(1)
+	case FIELD_NUM_GUESSES:
+		val := &p.Fields[FIELD_NUM_GUESSES].Value
+		if *val == MAX_GUESSES {
 			*val = 1
 		} else {
 			*val += 1
 		}
-	case 1: // number of guesses
-		val := &p.Fields[1].Value
-		if *val == MAX_GUESSES {
+	case FIELD_NUM_FAILS:
+		val := &p.Fields[FIELD_NUM_FAILS].Value
+		if *val == MAX_FAILS {
 			*val = 1
 		} else {
 			*val += 1
 		}
-	case 2: // number of failed words
-		val := &p.Fields[2].Value
-		if *val == MAX_FAILS {
+	case FIELD_HARD_MODE:
+		val := &p.Fields[FIELD_HARD_MODE].Value
+		if *val == FALSE {
+			*val = TRUE
+		} else {
+			*val = FALSE
+		}
+	case FIELD_DAILY:
+		val := &p.Fields[FIELD_DAILY].Value
+		if *val == FALSE {
+			*val = TRUE
+		} else {
+			*val = FALSE
+		}
+	case FIELD_BOARDS:
+		val := &p.Fields[FIELD_BOARDS].Value
+		if *val == MAX_BOARDS {
 			*val = 1
 		} else {
 			*val += 1
 		}
-	case 3: // hard-mode flag
-		val := &p.Fields[3].Value
+	case FIELD_ABSURD:
+		val := &p.Fields[FIELD_ABSURD].Value
+		if *val == FALSE {
+			*val = TRUE
+		} else {
+			*val = FALSE
+		}
+	case FIELD_HINTS:
+		val := &p.Fields[FIELD_HINTS].Value
+		if *val == MAX_HINTS {
+			*val = 0
+		} else {
+			*val += 1
+		}
+	case FIELD_MODE:
+		val := &p.Fields[FIELD_MODE].Value
+		*val = (*val + 1) % len(modeNames)
+	case FIELD_TIME_LIMIT:
+		val := &p.Fields[FIELD_TIME_LIMIT].Value
+		if *val == MAX_TIME_LIMIT {
+			*val = MIN_TIME_LIMIT
+		} else {
+			*val += TIME_LIMIT_STEP
+		}
+	case FIELD_THEME:
+		val := &p.Fields[FIELD_THEME].Value
+		*val = (p.Theme() + 1) % len(p.ThemeNames)
+	case FIELD_KEYBOARD:
+		val := &p.Fields[FIELD_KEYBOARD].Value
+		*val = (p.Keyboard() + 1) % len(p.KeyboardNames)
+	case FIELD_LANGUAGE:
+		p.setLanguage((p.Language() + 1) % len(p.Languages))
+	case FIELD_FOLD:
+		val := &p.Fields[FIELD_FOLD].Value
 		if *val == FALSE {
 			*val = TRUE
 		} else {
@@ -113,30 +477,95 @@ func (p *Parameters) DecCurField() {
 
 // NOTE: This must be updated when a menu item is added
 func (p *Parameters) DecValAtCorField() {
+	p.pendingSeed = nil // a changed setting is no longer the entered puzzle
 	switch p.CurEditingIdx {
-	case 0: // word length
-		val := &p.Fields[0].Value
-		if *val == p.MinWordLen {
-			*val = p.MaxWordLen
+	case FIELD_WORD_LEN:
+		p.stepWordLen(-1)
+	case FIELD_NUM_GUESSES:
+		val := &p.Fields[FIELD_NUM_GUESSES].Value
+		if *val == 1 {
+			*val = MAX_GUESSES
 		} else {
 			*val -= 1
 		}
-	case 1: // number of guesses
-		val := &p.Fields[1].Value
+	case FIELD_NUM_FAILS:
+		val := &p.Fields[FIELD_NUM_FAILS].Value
 		if *val == 1 {
-			*val = MAX_GUESSES
+			*val = MAX_FAILS
 		} else {
 			*val -= 1
 		}
-	case 2: // number of failed words
-		val := &p.Fields[2].Value
+	case FIELD_HARD_MODE:
+		val := &p.Fields[FIELD_HARD_MODE].Value
+		if *val == FALSE {
+			*val = TRUE
+		} else {
+			*val = FALSE
+		}
+	case FIELD_DAILY:
+		val := &p.Fields[FIELD_DAILY].Value
+		if *val == FALSE {
+			*val = TRUE
+		} else {
+			*val = FALSE
+		}
+	case FIELD_BOARDS:
+		val := &p.Fields[FIELD_BOARDS].Value
 		if *val == 1 {
-			*val = MAX_FAILS
+			*val = MAX_BOARDS
+		} else {
+			*val -= 1
+		}
+	case FIELD_ABSURD:
+		val := &p.Fields[FIELD_ABSURD].Value
+		if *val == FALSE {
+			*val = TRUE
+		} else {
+			*val = FALSE
+		}
+	case FIELD_HINTS:
+		val := &p.Fields[FIELD_HINTS].Value
+		if *val == 0 {
+			*val = MAX_HINTS
 		} else {
 			*val -= 1
 		}
-	case 3: // hard-mode flag
-		val := &p.Fields[3].Value
+	case FIELD_MODE:
+		val := &p.Fields[FIELD_MODE].Value
+		if *val == 0 {
+			*val = len(modeNames) - 1
+		} else {
+			*val -= 1
+		}
+	case FIELD_TIME_LIMIT:
+		val := &p.Fields[FIELD_TIME_LIMIT].Value
+		if *val == MIN_TIME_LIMIT {
+			*val = MAX_TIME_LIMIT
+		} else {
+			*val -= TIME_LIMIT_STEP
+		}
+	case FIELD_THEME:
+		val := &p.Fields[FIELD_THEME].Value
+		if p.Theme() == 0 {
+			*val = len(p.ThemeNames) - 1
+		} else {
+			*val = p.Theme() - 1
+		}
+	case FIELD_KEYBOARD:
+		val := &p.Fields[FIELD_KEYBOARD].Value
+		if p.Keyboard() == 0 {
+			*val = len(p.KeyboardNames) - 1
+		} else {
+			*val = p.Keyboard() - 1
+		}
+	case FIELD_LANGUAGE:
+		if p.Language() == 0 {
+			p.setLanguage(len(p.Languages) - 1)
+		} else {
+			p.setLanguage(p.Language() - 1)
+		}
+	case FIELD_FOLD:
+		val := &p.Fields[FIELD_FOLD].Value
 		if *val == FALSE {
 			*val = TRUE
 		} else {
@@ -145,27 +574,120 @@ func (p *Parameters) DecValAtCorField() {
 	}
 }
 
-var (
-	upBinds    []rune = []rune{'k', 'K', 'w', 'W'}
-	downBinds  []rune = []rune{'j', 'J', 's', 'S'}
-	leftBinds  []rune = []rune{'h', 'H', 'a', 'A'}
-	rightBinds []rune = []rune{'l', 'L', 'd', 'D'}
-)
+func (p *Parameters) HandleEventKey(ev *tcell.EventKey) MenuAction {
+	if p.pasting {
+		// pasted text can only be a puzzle code. it is never taken for menu keys
+		if p.EnteringCode && ev.Key() == tcell.KeyRune {
+			p.codeEventKey(ev)
+		}
+		return MENU_NONE
+	}
+	if p.EnteringCode {
+		return p.codeEventKey(ev)
+	}
+	p.Message = ""
+	if p.ShowingKeys {
+		p.ShowingKeys = false
+		return MENU_NONE
+	}
 
-func (p *Parameters) HandleEventKey(ev *tcell.EventKey, s tcell.Screen) bool {
-	if ev.Key() == tcell.KeyUp || slices.Contains(upBinds, ev.Rune()) {
+	switch p.Keymap.Action(CONTEXT_MENU, ev) {
+	case ACTION_NAV_UP:
 		p.IncCurField()
-	} else if ev.Key() == tcell.KeyDown || slices.Contains(downBinds, ev.Rune()) {
+	case ACTION_NAV_DOWN:
 		p.DecCurField()
-	} else if ev.Key() == tcell.KeyLeft || slices.Contains(leftBinds, ev.Rune()) {
+	case ACTION_NAV_LEFT:
 		p.DecValAtCorField()
-	} else if ev.Key() == tcell.KeyRight || slices.Contains(rightBinds, ev.Rune()) {
+	case ACTION_NAV_RIGHT:
+		p.IncValAtCurField()
+	case ACTION_START:
+		return MENU_START
+	case ACTION_PUZZLE_CODE:
+		p.EnteringCode = true
+		p.CodeInput = nil
+		p.CodeError = ""
+	case ACTION_STATS:
+		return MENU_STATS
+	case ACTION_RESUME:
+		if p.HasSavedGame {
+			return MENU_RESUME
+		}
+	case ACTION_QUIT:
+		return MENU_QUIT
+	case ACTION_KEYS:
+		p.ShowingKeys = true
+	}
+	return MENU_NONE
+}
+
+// HandleEventMouse acts on a click or scroll over the field at idx, or over no field when
+// idx is -1. Clicking selects a field and scrolling changes its value.
+func (p *Parameters) HandleEventMouse(ev *tcell.EventMouse, idx int) {
+	if p.EnteringCode || ev.Buttons() == tcell.ButtonNone {
+		return
+	}
+	p.Message = ""
+	if p.ShowingKeys {
+		p.ShowingKeys = false
+		return
+	}
+	if idx < 0 || idx >= len(p.Fields) {
+		return
+	}
+
+	switch ev.Buttons() {
+	case tcell.Button1:
+		p.CurEditingIdx = idx
+	case tcell.WheelUp:
+		p.CurEditingIdx = idx
 		p.IncValAtCurField()
-	} else if ev.Key() == tcell.KeyEnter {
-		return true
-	} else if ev.Key() == tcell.KeyCtrlC {
-		s.Fini()
-		os.Exit(0)
+	case tcell.WheelDown:
+		p.CurEditingIdx = idx
+		p.DecValAtCorField()
+	}
+}
+
+// HandleEventPaste starts or ends a paste. A paste into the menu is taken as a puzzle code.
+func (p *Parameters) HandleEventPaste(ev *tcell.EventPaste) {
+	p.pasting = ev.Start()
+	if ev.Start() && !p.EnteringCode {
+		p.ShowingKeys = false
+		p.EnteringCode = true
+		p.CodeInput = nil
+		p.CodeError = ""
 	}
-	return false
+}
+
+func (p *Parameters) codeEventKey(ev *tcell.EventKey) MenuAction {
+	switch p.Keymap.Action(CONTEXT_CODE, ev) {
+	case ACTION_CANCEL:
+		p.EnteringCode = false
+	case ACTION_BACKSPACE:
+		if len(p.CodeInput) > 0 {
+			p.CodeInput = p.CodeInput[:len(p.CodeInput)-1]
+		}
+		p.CodeError = ""
+	case ACTION_PLAY_CODE:
+		code, err := puzzle.Parse(string(p.CodeInput))
+		if err == nil {
+			err = p.ApplyPuzzleCode(code)
+		}
+		if err != nil {
+			p.CodeError = err.Error()
+			return MENU_NONE
+		}
+		p.EnteringCode = false
+		return MENU_START
+	default:
+		if ev.Key() == tcell.KeyRune && isCodeRune(ev.Rune()) {
+			p.CodeInput = append(p.CodeInput, unicode.ToUpper(ev.Rune()))
+			p.CodeError = ""
+		}
+	}
+	return MENU_NONE
+}
+
+// isCodeRune reports whether r can be typed into a puzzle code.
+func isCodeRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-'
 }
@@ -0,0 +1,69 @@
+package states
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
+	"gitlab.com/daneofmanythings/wohrdle/save"
+)
+
+// savedGame is what is written to disk when a game is suspended.
+type savedGame struct {
+	Fields  []Field         `json:"fields"`
+	Puzzle  string          `json:"puzzle"`
+	Elapsed time.Duration   `json:"elapsed"`
+	Session engine.Snapshot `json:"session"`
+
+	RunElapsed time.Duration   `json:"run_elapsed,omitempty"`
+	WordTimes  []time.Duration `json:"word_times,omitempty"`
+}
+
+func SaveGameSession(path string, gs *GameSession) error {
+	return save.Write(path, savedGame{
+		Fields:  gs.Parameters.Fields,
+		Puzzle:  gs.Puzzle.String(),
+		Elapsed: time.Since(gs.Started),
+		Session: gs.Snapshot(),
+
+		RunElapsed: time.Since(gs.RunStarted),
+		WordTimes:  gs.WordTimes,
+	})
+}
+
+// LoadGameSession resumes a suspended game. The menu is set to the settings of the saved game.
+func LoadGameSession(path string, params *Parameters) (*GameSession, error) {
+	saved := savedGame{}
+	if err := save.Read(path, &saved); err != nil {
+		return nil, err
+	}
+	code, err := puzzle.Parse(saved.Puzzle)
+	if err != nil {
+		return nil, err
+	}
+	// saves from before a setting was added leave it as it is
+	if len(saved.Fields) > len(params.Fields) {
+		return nil, fmt.Errorf("saved game has %d settings, expected at most %d", len(saved.Fields), len(params.Fields))
+	}
+
+	for i := range saved.Fields {
+		params.Fields[i].Value = saved.Fields[i].Value
+	}
+	if err := params.useLanguage(); err != nil {
+		return nil, err
+	}
+	session, err := engine.Restore(saved.Session, params.Answers(), params.ValidWords())
+	if err != nil {
+		return nil, err
+	}
+
+	return &GameSession{
+		GameSession: session,
+		Parameters:  *params,
+		Puzzle:      code,
+		Started:     time.Now().Add(-saved.Elapsed),
+		RunStarted:  time.Now().Add(-max(saved.RunElapsed, saved.Elapsed)),
+		WordTimes:   saved.WordTimes,
+	}, nil
+}
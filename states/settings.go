@@ -0,0 +1,144 @@
+package states
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitlab.com/daneofmanythings/wohrdle/config"
+)
+
+// ApplyDefaults sets the menu to the settings in d. A setting that is out of range is left
+// as it is and reported, by its name in the menu, along with any others in the returned error.
+// NOTE: This must be updated when menu items are added
+func (p *Parameters) ApplyDefaults(d config.Defaults) error {
+	errs := []error{}
+	setInt := func(idx int, val *int, check func(int) error) {
+		if val == nil {
+			return
+		}
+		if err := check(*val); err != nil {
+			errs = append(errs, err)
+			return
+		}
+		p.Fields[idx].Value = *val
+	}
+	setBool := func(idx int, val *bool) {
+		if val == nil {
+			return
+		}
+		p.Fields[idx].Value = FALSE
+		if *val {
+			p.Fields[idx].Value = TRUE
+		}
+	}
+
+	// the language goes first since the word lengths depend on it
+	if d.Language != nil {
+		names := []string{}
+		for _, l := range p.Languages {
+			names = append(names, l.Name)
+		}
+		if lang := slices.Index(names, strings.ToLower(*d.Language)); lang != -1 {
+			p.setLanguage(lang)
+		} else {
+			errs = append(errs, fmt.Errorf("language must be one of %s. got %q", strings.Join(names, ", "), *d.Language))
+		}
+	}
+	setInt(FIELD_WORD_LEN, d.WordLen, p.checkWordLen)
+	setInt(FIELD_NUM_GUESSES, d.NumGuesses, checkRange("num guesses", 1, MAX_GUESSES))
+	setInt(FIELD_NUM_FAILS, d.NumFails, checkRange("num failed words", 1, MAX_FAILS))
+	setBool(FIELD_HARD_MODE, d.HardMode)
+	setBool(FIELD_DAILY, d.Daily)
+	setInt(FIELD_BOARDS, d.NumBoards, checkRange("boards", 1, MAX_BOARDS))
+	setBool(FIELD_ABSURD, d.Absurd)
+	setInt(FIELD_HINTS, d.NumHints, checkRange("hints", 0, MAX_HINTS))
+	if d.Mode != nil {
+		if mode := slices.Index(modeNames, strings.ToLower(*d.Mode)); mode != -1 {
+			p.Fields[FIELD_MODE].Value = mode
+		} else {
+			errs = append(errs, fmt.Errorf("mode must be one of %s. got %q", strings.Join(modeNames, ", "), *d.Mode))
+		}
+	}
+	setInt(FIELD_TIME_LIMIT, d.TimeLimit, checkTimeLimit)
+	if d.Theme != nil {
+		if theme := slices.Index(p.ThemeNames, *d.Theme); theme != -1 {
+			p.Fields[FIELD_THEME].Value = theme
+		} else {
+			errs = append(errs, fmt.Errorf("theme must be one of %s. got %q", strings.Join(p.ThemeNames, ", "), *d.Theme))
+		}
+	}
+	if d.Keyboard != nil {
+		if keyboard := slices.Index(p.KeyboardNames, strings.ToLower(*d.Keyboard)); keyboard != -1 {
+			p.Fields[FIELD_KEYBOARD].Value = keyboard
+		} else {
+			errs = append(errs, fmt.Errorf("keyboard must be one of %s. got %q", strings.Join(p.KeyboardNames, ", "), *d.Keyboard))
+		}
+	}
+	setBool(FIELD_FOLD, d.FoldAccents)
+	return errors.Join(errs...)
+}
+
+// Defaults are the current settings of the menu, every one of them set.
+// NOTE: This must be updated when menu items are added
+func (p *Parameters) Defaults() config.Defaults {
+	intAt := func(idx int) *int {
+		val := p.Fields[idx].Value
+		return &val
+	}
+	boolAt := func(idx int) *bool {
+		val := p.Fields[idx].Value == TRUE
+		return &val
+	}
+	mode := modeNames[p.Mode()]
+	theme := p.ThemeNames[p.Theme()]
+	keyboard := p.KeyboardNames[p.Keyboard()]
+	language := p.Languages[p.Language()].Name
+	return config.Defaults{
+		WordLen:     intAt(FIELD_WORD_LEN),
+		NumGuesses:  intAt(FIELD_NUM_GUESSES),
+		NumFails:    intAt(FIELD_NUM_FAILS),
+		HardMode:    boolAt(FIELD_HARD_MODE),
+		Daily:       boolAt(FIELD_DAILY),
+		NumBoards:   intAt(FIELD_BOARDS),
+		Absurd:      boolAt(FIELD_ABSURD),
+		NumHints:    intAt(FIELD_HINTS),
+		Mode:        &mode,
+		TimeLimit:   intAt(FIELD_TIME_LIMIT),
+		Theme:       &theme,
+		Keyboard:    &keyboard,
+		Language:    &language,
+		FoldAccents: boolAt(FIELD_FOLD),
+	}
+}
+
+func (p *Parameters) checkWordLen(val int) error {
+	if val < p.MinWordLen || val > p.MaxWordLen {
+		return fmt.Errorf("word length must be between %d and %d. got %d", p.MinWordLen, p.MaxWordLen, val)
+	}
+	if len(p.WordRepo.Answers[strconv.Itoa(val)]) == 0 {
+		return fmt.Errorf("no words of length %d", val)
+	}
+	return nil
+}
+
+func checkRange(name string, lo, hi int) func(int) error {
+	return func(val int) error {
+		if val < lo || val > hi {
+			return fmt.Errorf("%s must be between %d and %d. got %d", name, lo, hi, val)
+		}
+		return nil
+	}
+}
+
+func checkTimeLimit(val int) error {
+	if err := checkRange("time limit", MIN_TIME_LIMIT, MAX_TIME_LIMIT)(val); err != nil {
+		return err
+	}
+	if val%TIME_LIMIT_STEP != 0 {
+		return fmt.Errorf("time limit must be a multiple of %d seconds. got %d", TIME_LIMIT_STEP, val)
+	}
+	return nil
+}
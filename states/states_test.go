@@ -2,210 +2,903 @@ package states
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/config"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/multiplayer"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
 	"gitlab.com/daneofmanythings/wohrdle/utils"
 )
 
-const (
-	wordTests string = "tests"
-	wordWrong string = "wrong"
-	wordVolts string = "volts"
-)
+// mockParameters is the menu of a word list that is known to have answers
+func mockParameters(params *Parameters, err error) *Parameters {
+	if err != nil {
+		panic(err)
+	}
+	return params
+}
+
+// mockNewGameSession always targets word. others are only allowed as guesses
+func mockNewGameSession(word string, others ...string) *GameSession {
+	length := fmt.Sprintf("%d", len(word))
+	wordRepo := utils.WordRepository{
+		Answers: map[string][]string{length: {word}},
+		Allowed: map[string][]string{length: append([]string{word}, others...)},
+	}
+	params := mockParameters(NewDefaultParameters(wordRepo))
+	params.Fields[FIELD_WORD_LEN].Value = len(word)
+	gs, err := NewGameSession(params)
+	if err != nil {
+		panic(err)
+	}
+	return gs
+}
+
+func typeWord(gs *GameSession, word string) {
+	for _, r := range word {
+		gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+}
+
+func TestHandleEventKeyVictory(t *testing.T) {
+	gs := mockNewGameSession("tests")
+	typeWord(gs, "tests")
+
+	if gs.GetState() != engine.VICTORY {
+		t.Fatalf("victory was not detected. state=%v", gs.GetState())
+	}
+	expected := "TESTS is correct! [c]ontinue | [s]hare | [b]ack | code: " + gs.Puzzle.String()
+	if gs.HelpText != expected {
+		t.Fatalf("unexpected help text. got=%q, expected=%q", gs.HelpText, expected)
+	}
+	if shouldExit := gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone)); shouldExit {
+		t.Fatal("continue should not exit the game")
+	}
+	if gs.GetState() != engine.ACTIVE || gs.HelpText != "" {
+		t.Fatal("continue did not reset the game")
+	}
+}
+
+func TestHandleEventKeyFailedEntry(t *testing.T) {
+	gs := mockNewGameSession("tests")
+	typeWord(gs, "zzzzz")
+
+	expected := "ZZZZZ not in word list. 4 failed entries left"
+	if gs.HelpText != expected {
+		t.Fatalf("unexpected help text. got=%q, expected=%q", gs.HelpText, expected)
+	}
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+	if gs.HelpText != "" || gs.CurrentGuess() != "ZZZZ" {
+		t.Fatalf("backspace did not edit the rejected guess. guess=%s", gs.CurrentGuess())
+	}
+}
+
+func TestHandleEventKeyGiveUp(t *testing.T) {
+	gs := mockNewGameSession("tests")
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModNone))
+
+	if gs.GetState() != engine.LOSS {
+		t.Fatalf("giving up did not end the game. state=%v", gs.GetState())
+	}
+	if shouldExit := gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone)); !shouldExit {
+		t.Fatal("going back did not exit the game")
+	}
+}
+
+func TestMultiBoardLoss(t *testing.T) {
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests", "toast"}})))
+	params.Fields[FIELD_NUM_GUESSES].Value = 1
+	params.Fields[FIELD_BOARDS].Value = 2
+	gs, err := NewGameSession(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gs.Boards) != 2 || gs.NumGuesses != 2 {
+		t.Fatalf("unexpected boards. boards=%d, guesses=%d", len(gs.Boards), gs.NumGuesses)
+	}
+
+	// solving one board leaves only the other in the loss message
+	solved := gs.Boards[0].Target()
+	typeWord(gs, solved)
+	typeWord(gs, solved)
+	if gs.GetState() != engine.LOSS {
+		t.Fatalf("the game did not end. state=%v", gs.GetState())
+	}
+	if !strings.HasPrefix(gs.HelpText, gs.Boards[1].Target()+" was the word!") {
+		t.Fatalf("unexpected help text. got=%q", gs.HelpText)
+	}
+}
+
+func TestDailyGameOver(t *testing.T) {
+	gs := mockNewGameSession("tests", "toast")
+	gs.Parameters.Fields[FIELD_DAILY].Value = TRUE
+	gs.GiveUp()
 
-func mockNewGameSession(word string) *GameSession {
-	wordRepo := map[string][]string{
-		fmt.Sprintf("%d", len(word)): {
-			word,
-		},
+	if gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone)); gs.GetState() != engine.LOSS {
+		t.Fatal("a daily puzzle should not continue into another game")
 	}
-	params := NewDefaultParameters(wordRepo)
-	return NewGameSession(params)
 }
 
-func TestIsValidWord(t *testing.T) {
-	gs := mockNewGameSession(wordTests)
-	var convenience_word_string string
-	for _, r := range wordTests {
-		gs.PushRune(r)
-		convenience_word_string += utils.RuneToAlpha(r)
+func TestDailyParameters(t *testing.T) {
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests", "toast", "adieu"}})))
+	params.Fields[FIELD_DAILY].Value = TRUE
+
+	first, second := params.NextPuzzle(), params.NextPuzzle()
+	if first != second {
+		t.Fatalf("daily puzzles do not agree. first=%v, second=%v", first, second)
 	}
+}
+
+func TestPuzzleCodeEntry(t *testing.T) {
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"4": {"test", "work"}, "5": {"tests", "toast", "adieu"}})))
+	code := puzzle.Code{Seed: 7, WordLen: 4, NumGuesses: 3, MaxNumFails: 2, HardMode: true, NumBoards: 1}
 
-	if !gs.isValidWord() {
-		t.Fatalf("word=%s was not found in validWords", convenience_word_string)
+	params.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone))
+	for _, r := range code.String() {
+		params.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	if action := params.HandleEventKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)); action != MENU_START {
+		t.Fatalf("a valid code did not start the game. err=%s", params.CodeError)
+	}
+
+	gs, err := NewGameSession(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.Puzzle != code {
+		t.Fatalf("unexpected puzzle. got=%v, expected=%v", gs.Puzzle, code)
+	}
+	cfg, err := code.Config(params.Answers(), params.ValidWords())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.Target() != strings.ToUpper(cfg.Targets[0]) {
+		t.Fatalf("the code did not reproduce the target. got=%s", gs.Target())
+	}
+	if params.pendingSeed != nil {
+		t.Fatal("the code would be played more than once")
 	}
 }
 
-func TestIsWinner(t *testing.T) {
-	gs := mockNewGameSession(wordTests)
-	for _, r := range wordTests {
-		gs.PushRune(r)
+func TestPuzzleCodeEntryErrors(t *testing.T) {
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests"}})))
+	params.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone))
+	for _, r := range (puzzle.Code{Seed: 7, WordLen: 4, NumGuesses: 3, MaxNumFails: 2, NumBoards: 1}).String() {
+		params.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
 	}
 
-	if !gs.IsWinner() {
-		t.Fatal("winner was not detected")
+	if action := params.HandleEventKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)); action != MENU_NONE {
+		t.Fatal("a code for a missing word length started the game")
+	}
+	if params.CodeError == "" || !params.EnteringCode {
+		t.Fatal("the error was not reported")
 	}
+}
 
-	for i := 0; i < len(wordTests); i++ {
-		gs.PopRune()
+func TestPuzzleCodeEntryKeymap(t *testing.T) {
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests"}})))
+	km, err := LoadKeymap(map[string][]string{"cancel": {"f2"}})
+	if err != nil {
+		t.Fatalf("could not load the keymap: %v", err)
 	}
+	params.Keymap = km
 
-	for _, r := range wordWrong {
-		gs.PushRune(r)
+	params.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone))
+	params.HandleEventKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if !params.EnteringCode {
+		t.Fatal("the replaced cancel key still left the code entry")
+	}
+	params.HandleEventKey(tcell.NewEventKey(tcell.KeyF2, 0, tcell.ModNone))
+	if params.EnteringCode {
+		t.Fatal("the rebound cancel key did not leave the code entry")
 	}
-	if gs.IsWinner() {
-		t.Fatal("winner was incorrectly detected")
+	if summary := km.Summary(CONTEXT_CODE, ACTION_PLAY_CODE, ACTION_CANCEL); summary != "<Enter> play the puzzle | <F2> cancel" {
+		t.Fatalf("wrong help. got=%q", summary)
 	}
 }
 
-func TestFinalizeCurRow(t *testing.T) {
-	// WARN: these tests do not use the word constants because the output is also
-	// dependant the guess.
-	testCases := []struct {
-		name  string
-		word  string
-		guess string
-		row   []Cell
+func TestRecord(t *testing.T) {
+	gs := mockNewGameSession("tests", "toast")
+	typeWord(gs, "zzzzz")
+	gs.ClearCurrentGuess()
+	typeWord(gs, "toast")
+	typeWord(gs, "tests")
+
+	rec := gs.Record()
+	if !rec.Won || rec.Target != "TESTS" || rec.FailsUsed != 1 || rec.WordLen != 5 {
+		t.Fatalf("unexpected record. got=%+v", rec)
+	}
+	if len(rec.Guesses) != 2 || rec.Guesses[0] != "TOAST" || rec.Puzzle != gs.Puzzle.String() {
+		t.Fatalf("unexpected record guesses. got=%v", rec.Guesses)
+	}
+}
+
+func TestSuspendAndResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	gs := mockNewGameSession("tests", "toast", "adieu")
+	typeWord(gs, "toast")
+	gs.PushRune('t')
+
+	if shouldExit := gs.HandleEventKey(tcell.NewEventKey(tcell.KeyCtrlZ, 0, tcell.ModNone)); !shouldExit || !gs.Suspended {
+		t.Fatal("suspending did not leave the game")
+	}
+	if err := SaveGameSession(path, gs); err != nil {
+		t.Fatal(err)
+	}
+
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests", "toast", "adieu"}})))
+	params.Fields[FIELD_NUM_GUESSES].Value = 3
+	resumed, err := LoadGameSession(path, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Target() != gs.Target() || resumed.Puzzle != gs.Puzzle || resumed.CurrentGuess() != "T" {
+		t.Fatalf("resumed game does not match. target=%s, puzzle=%v", resumed.Target(), resumed.Puzzle)
+	}
+	if params.Fields[FIELD_NUM_GUESSES].Value != gs.Parameters.Fields[FIELD_NUM_GUESSES].Value {
+		t.Fatal("the menu was not set to the saved settings")
+	}
+}
+
+func TestWordLenSkipsMissingLengths(t *testing.T) {
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{
+		"3": {"tea"},
+		"6": {"toasts"},
+		"8": {"sandwich"},
+	})))
+	if params.Fields[FIELD_WORD_LEN].Value != 3 {
+		t.Fatalf("missing default length was not replaced. got=%d", params.Fields[FIELD_WORD_LEN].Value)
+	}
+
+	expected := []int{6, 8, 3}
+	for _, length := range expected {
+		params.IncValAtCurField()
+		if params.Fields[FIELD_WORD_LEN].Value != length {
+			t.Fatalf("unexpected word length. got=%d, expected=%d", params.Fields[FIELD_WORD_LEN].Value, length)
+		}
+	}
+	params.DecValAtCorField()
+	if params.Fields[FIELD_WORD_LEN].Value != 8 {
+		t.Fatalf("unexpected word length. got=%d, expected=8", params.Fields[FIELD_WORD_LEN].Value)
+	}
+}
+
+func TestHintKeys(t *testing.T) {
+	params := mockNewGameSession("tests", "toast").Parameters
+	params.Fields[FIELD_HINTS].Value = 1
+	gs, err := NewGameSession(&params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModNone))
+	if !strings.HasPrefix(gs.HelpText, "Hint: letter 1 is T.") || gs.CurrentGuess() != "" {
+		t.Fatalf("unexpected help text. got=%q, guess=%s", gs.HelpText, gs.CurrentGuess())
+	}
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, '#', tcell.ModNone))
+	if gs.HelpText != "No hints left." {
+		t.Fatalf("unexpected help text. got=%q", gs.HelpText)
+	}
+
+	typeWord(gs, "tests")
+	if !strings.Contains(gs.HelpText, "1/1 hints used") || gs.Record().HintsUsed != 1 {
+		t.Fatalf("the hints used were not reported. got=%q", gs.HelpText)
+	}
+}
+
+func TestCountdownTimeOut(t *testing.T) {
+	params := mockNewGameSession("tests", "toast").Parameters
+	params.Fields[FIELD_MODE].Value = MODE_COUNTDOWN
+	gs, err := NewGameSession(&params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gs.Tick(gs.Started.Add(params.TimeLimit() - time.Second)); gs.GetState() != engine.ACTIVE {
+		t.Fatal("the game timed out early")
+	}
+	gs.Tick(gs.Deadline())
+	if gs.GetState() != engine.TIMEOUT || !strings.HasPrefix(gs.HelpText, "Time's up! TESTS was the word!") {
+		t.Fatalf("the game did not time out. state=%v, help=%q", gs.GetState(), gs.HelpText)
+	}
+	if gs.Remaining(time.Now().Add(time.Hour)) != 0 || !gs.Record().TimedOut {
+		t.Fatal("the time out was not recorded")
+	}
+
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if gs.GetState() != engine.ACTIVE || gs.Remaining(gs.Started) != params.TimeLimit() {
+		t.Fatalf("continuing did not restart the clock. state=%v", gs.GetState())
+	}
+}
+
+func TestResetError(t *testing.T) {
+	gs := mockNewGameSession("tests")
+	gs.GiveUp()
+	// there are no words of the length to start a new game with
+	gs.Parameters.Fields[FIELD_WORD_LEN].Value = 4
+
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if gs.GetState() != engine.LOSS || !strings.HasPrefix(gs.HelpText, "Could not start a new game: ") {
+		t.Fatalf("the error was not reported. state=%v, help=%q", gs.GetState(), gs.HelpText)
+	}
+}
+
+func TestSpeedrunAdvance(t *testing.T) {
+	params := mockNewGameSession("tests", "toast").Parameters
+	params.Fields[FIELD_DAILY].Value = TRUE // ignored, every word of a speedrun is different
+	params.Fields[FIELD_MODE].Value = MODE_SPEEDRUN
+	gs, err := NewGameSession(&params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := gs.Deadline()
+
+	typeWord(gs, "tests")
+	if !gs.Advance() || gs.GetState() != engine.ACTIVE || len(gs.WordTimes) != 1 {
+		t.Fatalf("the speedrun did not move on. state=%v, solved=%d", gs.GetState(), len(gs.WordTimes))
+	}
+	if !strings.HasPrefix(gs.HelpText, "TESTS is correct! 1 solved") || gs.Deadline() != deadline {
+		t.Fatalf("unexpected help text. got=%q", gs.HelpText)
+	}
+
+	gs.Tick(deadline)
+	if gs.GetState() != engine.TIMEOUT || gs.Advance() {
+		t.Fatalf("the speedrun did not end. state=%v", gs.GetState())
+	}
+	if !strings.HasPrefix(gs.HelpText, "Time's up! 1 solved") {
+		t.Fatalf("unexpected help text. got=%q", gs.HelpText)
+	}
+}
+
+func TestSpeedrunGiveUp(t *testing.T) {
+	params := mockNewGameSession("tests", "toast").Parameters
+	params.Fields[FIELD_MODE].Value = MODE_SPEEDRUN
+	gs, err := NewGameSession(&params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typeWord(gs, "tests")
+	gs.Advance()
+	gs.GiveUp()
+	if gs.Advance() || gs.GetState() != engine.LOSS || len(gs.WordTimes) != 1 {
+		t.Fatalf("giving up did not end the speedrun. state=%v, solved=%d", gs.GetState(), len(gs.WordTimes))
+	}
+}
+
+func TestSurvivalRun(t *testing.T) {
+	params := mockNewGameSession("tests", "toast").Parameters
+	params.Fields[FIELD_MODE].Value = MODE_SURVIVAL
+	gs, err := NewGameSession(&params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// solved in 2 of 6 guesses, so 4 carry over with 2 bonus guesses
+	typeWord(gs, "zzzzz")
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	typeWord(gs, "toast")
+	typeWord(gs, "tests")
+	if !strings.HasPrefix(gs.HelpText, "TESTS is correct! +2 bonus, 6 guesses for the next word.") {
+		t.Fatalf("unexpected help text. got=%q", gs.HelpText)
+	}
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if gs.GetState() != engine.ACTIVE || gs.GuessesLeft() != 6 || gs.MaxNumFails != 4 || len(gs.WordTimes) != 1 {
+		t.Fatalf("the run did not carry over. guesses=%d, fails=%d", gs.GuessesLeft(), gs.MaxNumFails)
+	}
+
+	for i := 0; i < 6; i++ {
+		typeWord(gs, "toast")
+	}
+	if gs.GetState() != engine.LOSS || !strings.Contains(gs.HelpText, "Run over! 1 solved") {
+		t.Fatalf("the run did not end. state=%v, help=%q", gs.GetState(), gs.HelpText)
+	}
+
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if gs.GuessesLeft() != 6 || gs.MaxNumFails != 5 || len(gs.WordTimes) != 0 {
+		t.Fatalf("a new run did not start over. guesses=%d, fails=%d", gs.GuessesLeft(), gs.MaxNumFails)
+	}
+}
+
+func TestSurvivalContinueError(t *testing.T) {
+	params := mockNewGameSession("tests", "toast").Parameters
+	params.Fields[FIELD_MODE].Value = MODE_SURVIVAL
+	gs, err := NewGameSession(&params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeWord(gs, "tests")
+	// there are no words of the length to go on with
+	gs.Parameters.Fields[FIELD_WORD_LEN].Value = 4
+
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if gs.GetState() != engine.VICTORY || !strings.HasPrefix(gs.HelpText, "Could not start the next word: ") {
+		t.Fatalf("the error was not reported. state=%v, help=%q", gs.GetState(), gs.HelpText)
+	}
+}
+
+func TestShareKey(t *testing.T) {
+	gs := mockNewGameSession("tests", "toast")
+	typeWord(gs, "toast")
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone))
+	if gs.Sharing {
+		t.Fatalf("sharing while the game is still being played")
+	}
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+
+	typeWord(gs, "tests")
+	gs.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone))
+	if !gs.Sharing {
+		t.Fatalf("the result was not shared")
+	}
+	expected := "Wohrdle classic 5 letters 2/6\n\n🟩⬛⬛🟨🟨\n🟩🟩🟩🟩🟩"
+	if got := gs.ShareText(); got != expected {
+		t.Fatalf("wrong share text. got=%q, expected=%q", got, expected)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	params := mockNewGameSession("tests").Parameters
+	wordLen, guesses, fails, boards := 5, 10, MAX_FAILS+1, 0
+	hardMode, mode := true, "Speedrun"
+
+	err := params.ApplyDefaults(config.Defaults{
+		WordLen:    &wordLen,
+		NumGuesses: &guesses,
+		NumFails:   &fails,
+		NumBoards:  &boards,
+		HardMode:   &hardMode,
+		Mode:       &mode,
+	})
+	if err == nil {
+		t.Fatal("out of range settings were accepted")
+	}
+	for _, expected := range []string{"num failed words must be between 1 and 20. got 21", "boards must be between 1 and 8. got 0"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("unclear error. got=%q, expected it to contain %q", err, expected)
+		}
+	}
+	if params.Fields[FIELD_NUM_GUESSES].Value != 10 || params.Fields[FIELD_HARD_MODE].Value != TRUE || params.Mode() != MODE_SPEEDRUN {
+		t.Fatalf("the valid settings were not applied. got=%v", params.Fields)
+	}
+	if params.Fields[FIELD_NUM_FAILS].Value != 5 || params.Fields[FIELD_BOARDS].Value != 1 {
+		t.Fatalf("the invalid settings were applied. got=%v", params.Fields)
+	}
+
+	wordLen = 6
+	if err := params.ApplyDefaults(config.Defaults{WordLen: &wordLen}); err == nil || params.Fields[FIELD_WORD_LEN].Value != 5 {
+		t.Fatalf("a word length without words was accepted. err=%v", err)
+	}
+}
+
+func TestDefaultsRoundTrip(t *testing.T) {
+	params := mockNewGameSession("tests").Parameters
+	params.Fields[FIELD_NUM_GUESSES].Value = 9
+	params.Fields[FIELD_ABSURD].Value = TRUE
+	params.Fields[FIELD_MODE].Value = MODE_COUNTDOWN
+	params.Fields[FIELD_TIME_LIMIT].Value = 60
+	params.Fields[FIELD_FOLD].Value = FALSE
+
+	restored := mockNewGameSession("tests").Parameters
+	if err := restored.ApplyDefaults(params.Defaults()); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(restored.Fields, params.Fields) {
+		t.Fatalf("round trip failed. got=%v, expected=%v", restored.Fields, params.Fields)
+	}
+}
+
+func TestThemeField(t *testing.T) {
+	params := mockNewGameSession("tests").Parameters
+	params.ThemeNames = []string{"default", "colorblind", "mine"}
+	params.CurEditingIdx = FIELD_THEME
+
+	params.DecValAtCorField()
+	if params.Theme() != 2 || params.FieldText(FIELD_THEME) != "mine" {
+		t.Fatalf("the theme did not wrap around. got=%s", params.FieldText(FIELD_THEME))
+	}
+	params.IncValAtCurField()
+	if params.Theme() != 0 {
+		t.Fatalf("the theme did not wrap around. got=%s", params.FieldText(FIELD_THEME))
+	}
+
+	theme := "colorblind"
+	if err := params.ApplyDefaults(config.Defaults{Theme: &theme}); err != nil || params.Theme() != 1 {
+		t.Fatalf("the theme was not set. err=%v", err)
+	}
+	theme = "nope"
+	if err := params.ApplyDefaults(config.Defaults{Theme: &theme}); err == nil || params.Theme() != 1 {
+		t.Fatalf("an unknown theme was accepted. err=%v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Key
 	}{
-		{
-			"sanityCheck",
-			"volts",
-			"volts",
-			[]Cell{{'V', CORRECT}, {'O', CORRECT}, {'L', CORRECT}, {'T', CORRECT}, {'S', CORRECT}},
-		},
-		{
-			"twoInputOneOutputPreceeding",
-			"volts",
-			"lusts",
-			[]Cell{{'L', PARTIAL}, {'U', USED}, {'S', USED}, {'T', CORRECT}, {'S', CORRECT}},
-		},
-		{
-			"twoInputOneOutputFollowing",
-			"stims",
-			"sassy",
-			[]Cell{{'S', CORRECT}, {'A', USED}, {'S', PARTIAL}, {'S', USED}, {'Y', USED}},
-		},
-		{
-			"twoInputOneOutputFollowingWithOnePartial",
-			"stims",
-			"sissy",
-			[]Cell{{'S', CORRECT}, {'I', PARTIAL}, {'S', PARTIAL}, {'S', USED}, {'Y', USED}},
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			gs := mockNewGameSession(tc.word)
-			for _, r := range tc.guess {
-				gs.PushRune(r)
-			}
-			gs.finalizeCurRow()
-			gs.curIdx -= 1
-			curRow := gs.Grid[gs.curIdx]
-			for i := range curRow {
-				if !curRow[i].isEqualTo(tc.row[i]) {
-					t.Fatalf(
-						"unexpected cell value at %d, got=%v. expected=%v. word=%s, guess=%s",
-						i,
-						curRow[i],
-						tc.row[i],
-						tc.word,
-						tc.guess,
-					)
-				}
-			}
-		})
-	}
-}
-
-func TestIsHardModeSatisfied(t *testing.T) {
-	testCases := []struct {
-		name        string
-		word        string
-		firstGuess  string
-		secondGuess string
-		expected    bool
+		{"k", Key{Key: tcell.KeyRune, Rune: 'k'}},
+		{"?", Key{Key: tcell.KeyRune, Rune: '?'}},
+		{"space", Key{Key: tcell.KeyRune, Rune: ' '}},
+		{"enter", Key{Key: tcell.KeyEnter}},
+		{"Return", Key{Key: tcell.KeyEnter}},
+		{"esc", Key{Key: tcell.KeyEscape}},
+		{"backspace", Key{Key: tcell.KeyBackspace}},
+		{"F1", Key{Key: tcell.KeyF1}},
+		{"ctrl-c", Key{Key: tcell.KeyCtrlC}},
+	}
+	for _, tt := range tests {
+		key, err := ParseKey(tt.input)
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", tt.input, err)
+		}
+		if key != tt.expected {
+			t.Fatalf("wrong key for %q. got=%v, expected=%v", tt.input, key, tt.expected)
+		}
+	}
+	if _, err := ParseKey("hyper-x"); err == nil {
+		t.Fatal("an unknown key was parsed")
+	}
+}
+
+func TestDefaultKeymapHasNoConflicts(t *testing.T) {
+	if err := DefaultKeymap().Conflicts(); err != nil {
+		t.Fatalf("the default keymap conflicts: %v", err)
+	}
+}
+
+func TestLoadKeymap(t *testing.T) {
+	km, err := LoadKeymap(map[string][]string{"give_up": {"ctrl-q"}, "nav_up": {"i"}})
+	if err != nil {
+		t.Fatalf("could not load the keymap: %v", err)
+	}
+	if action := km.Action(CONTEXT_GAME, tcell.NewEventKey(tcell.KeyCtrlQ, 0, tcell.ModNone)); action != ACTION_GIVE_UP {
+		t.Fatalf("rebound key does the wrong thing. got=%v, expected=%v", action, ACTION_GIVE_UP)
+	}
+	if action := km.Action(CONTEXT_MENU, tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone)); action != ACTION_NONE {
+		t.Fatalf("a replaced default is still bound. got=%v", action)
+	}
+	if action := km.Action(CONTEXT_GAME_OVER, tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModNone)); action != ACTION_BACK {
+		t.Fatalf("keys of other actions changed. got=%v, expected=%v", action, ACTION_BACK)
+	}
+
+	tests := []struct {
+		keys     map[string][]string
+		expected string
 	}{
-		{
-			name:        "sanity check",
-			word:        "tests",
-			firstGuess:  "toast",
-			secondGuess: "toast",
-			expected:    true,
-		},
-		{
-			name:        "missing correct char",
-			word:        "tests",
-			firstGuess:  "toast",
-			secondGuess: "strap",
-			expected:    false,
-		},
-		{
-			name:        "two missing correct char",
-			word:        "tests",
-			firstGuess:  "tales",
-			secondGuess: "strap",
-			expected:    false,
-		},
-		{
-			name:        "missing partial, no correct",
-			word:        "tests",
-			firstGuess:  "adieu",
-			secondGuess: "short",
-			expected:    false,
-		},
-		{
-			name:        "two missing partial, no correct",
-			word:        "tests",
-			firstGuess:  "stick",
-			secondGuess: "pound",
-			expected:    false,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			gs := mockNewGameSession(tc.word)
-			for _, r := range tc.firstGuess {
-				gs.PushRune(r)
-			}
-			gs.finalizeCurRow()
-			for _, r := range tc.secondGuess {
-				gs.PushRune(r)
-			}
-			if gs.isHardModeSatisfied() != tc.expected {
-				t.Fatalf("HardMode validation failure.\nword=%s\nfirst=%s\nsecond=%s\nexpected=%v\ngot=%v",
-					tc.word,
-					tc.firstGuess,
-					tc.secondGuess,
-					tc.expected,
-					gs.isHardModeSatisfied(),
-				)
-			}
-		})
-	}
-}
-
-func TestCountByRune(t *testing.T) {
-	gs := mockNewGameSession(wordTests)
-	for _, r := range gs.targetWordAsRunes {
-		gs.PushRune(r)
-	}
-	// WARN: this is done manually. if wordTests changes, this will also need to change
-	targetMap := map[rune]int{
-		'T': 2,
-		'E': 1,
-		'S': 2,
-	}
-	recievedMap := gs.countMapForTargetWord()
-	for k := range targetMap {
-		if targetMap[k] != recievedMap[k] {
-			t.Fatalf("unexpected count map for %s:\n%v\nexpected:\n%v", gs.targetWordAsString, recievedMap, targetMap)
+		{map[string][]string{"jump": {"j"}}, `unknown action "jump"`},
+		{map[string][]string{"hint_count": {"hyper-x"}}, `hint_count: unknown key "hyper-x"`},
+		{map[string][]string{"share": {"c"}}, "c is bound to both continue and share in the game over"},
+		{map[string][]string{"hint_letter": {"x"}}, "x is bound to hint_letter in the game, where it is typed"},
+		{map[string][]string{"cancel": {"-"}}, "- is bound to cancel in the puzzle code, where it is typed"},
+	}
+	for _, tt := range tests {
+		_, err := LoadKeymap(tt.keys)
+		if err == nil || err.Error() != tt.expected {
+			t.Fatalf("wrong error for %v. got=%v, expected=%v", tt.keys, err, tt.expected)
+		}
+	}
+}
+
+func TestKeymapLabel(t *testing.T) {
+	km := DefaultKeymap()
+	tests := []struct {
+		action   Action
+		expected string
+	}{
+		{ACTION_CONTINUE, "[c]ontinue"},
+		{ACTION_STATS, "s[t]ats"},
+		{ACTION_START, "<Enter> start"},
+		{ACTION_GIVE_UP, "<Ctrl-C> give up"},
+	}
+	for _, tt := range tests {
+		if label := km.Label(tt.action); label != tt.expected {
+			t.Fatalf("wrong label. got=%q, expected=%q", label, tt.expected)
+		}
+	}
+}
+
+func TestMenuMouse(t *testing.T) {
+	p := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests"}})))
+	p.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.Button1, tcell.ModNone), 5)
+	if p.CurEditingIdx != 5 {
+		t.Fatalf("clicking did not select the field. got=%d, expected=%d", p.CurEditingIdx, 5)
+	}
+	p.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.WheelUp, tcell.ModNone), 1)
+	if p.CurEditingIdx != 1 || p.Fields[FIELD_NUM_GUESSES].Value != 7 {
+		t.Fatalf("scrolling up did not raise the value. field=%d, value=%d", p.CurEditingIdx, p.Fields[FIELD_NUM_GUESSES].Value)
+	}
+	p.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.WheelDown, tcell.ModNone), 1)
+	p.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.WheelDown, tcell.ModNone), 1)
+	if p.Fields[FIELD_NUM_GUESSES].Value != 5 {
+		t.Fatalf("scrolling down did not lower the value. got=%d, expected=%d", p.Fields[FIELD_NUM_GUESSES].Value, 5)
+	}
+	p.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.Button1, tcell.ModNone), -1)
+	if p.CurEditingIdx != 1 {
+		t.Fatalf("clicking outside the fields changed the selection. got=%d", p.CurEditingIdx)
+	}
+}
+
+func TestMenuPaste(t *testing.T) {
+	p := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests"}})))
+	p.HandleEventPaste(tcell.NewEventPaste(true))
+	for _, r := range "ab-1\n" {
+		key := tcell.KeyRune
+		if r == '\n' {
+			key = tcell.KeyEnter
+		}
+		if action := p.HandleEventKey(tcell.NewEventKey(key, r, tcell.ModNone)); action != MENU_NONE {
+			t.Fatalf("a pasted key was taken as a menu key. got=%v", action)
 		}
 	}
-	for k := range recievedMap {
-		if targetMap[k] != recievedMap[k] {
-			t.Fatalf("unexpected count map for %s:\n%v\nexpected:\n%v", gs.targetWordAsString, recievedMap, targetMap)
+	p.HandleEventPaste(tcell.NewEventPaste(false))
+	if !p.EnteringCode || string(p.CodeInput) != "AB-1" {
+		t.Fatalf("the paste was not entered as a code. entering=%v, code=%q", p.EnteringCode, string(p.CodeInput))
+	}
+}
+
+func TestGameMouseAndPaste(t *testing.T) {
+	gs := mockNewGameSession("tests")
+	gs.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.Button1, tcell.ModNone), 'T')
+	gs.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.ButtonNone, tcell.ModNone), 'T')
+	gs.HandleEventMouse(tcell.NewEventMouse(0, 0, tcell.Button1, tcell.ModNone), 0)
+	if gs.CurrentGuess() != "T" {
+		t.Fatalf("clicking a key did not type it once. got=%q, expected=%q", gs.CurrentGuess(), "T")
+	}
+
+	gs.HandleEventPaste(tcell.NewEventPaste(true))
+	for _, r := range "est s\n" {
+		key := tcell.KeyRune
+		if r == '\n' {
+			key = tcell.KeyEnter
 		}
+		gs.HandleEventKey(tcell.NewEventKey(key, r, tcell.ModNone))
+	}
+	gs.HandleEventPaste(tcell.NewEventPaste(false))
+	if gs.CurrentGuess() != "TESTS" || gs.GuessesUsed() != 0 {
+		t.Fatalf("the paste was not typed without submitting. guess=%q, used=%d", gs.CurrentGuess(), gs.GuessesUsed())
+	}
+}
+
+func mockLanguages() []Language {
+	return []Language{
+		{Code: "en", Name: "english", Repo: utils.NewWordRepository(map[string][]string{"5": {"tests"}, "6": {"toasts"}})},
+		{Code: "es", Name: "spanish", Repo: utils.WordRepository{
+			Alphabet: "ABCDEFGHIJKLMNÑOPQRSTUVWXYZ",
+			Answers:  map[string][]string{"4": {"niño"}, "5": {"señor"}},
+			Allowed:  map[string][]string{"4": {"niño"}, "5": {"señor"}},
+		}},
+	}
+}
+
+func TestLanguageField(t *testing.T) {
+	params := mockParameters(NewParameters(mockLanguages()))
+	params.Fields[FIELD_WORD_LEN].Value = 6
+	params.CurEditingIdx = FIELD_LANGUAGE
+	params.IncValAtCurField()
+	if params.FieldText(FIELD_LANGUAGE) != "spanish" || params.Fields[FIELD_WORD_LEN].Value != 5 || params.MinWordLen != 4 {
+		t.Fatalf("switching language did not switch word lists. language=%s, length=%d, min=%d",
+			params.FieldText(FIELD_LANGUAGE), params.Fields[FIELD_WORD_LEN].Value, params.MinWordLen)
+	}
+
+	gs, err := NewGameSession(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeWord(gs, "seéñor")
+	if gs.GetState() != engine.VICTORY {
+		t.Fatalf("the word was not guessed with the letters of the alphabet. guesses=%v", gs.Guesses())
+	}
+	params.DecValAtCorField()
+	if params.FieldText(FIELD_LANGUAGE) != "english" {
+		t.Fatalf("language did not wrap around. got=%s", params.FieldText(FIELD_LANGUAGE))
+	}
+}
+
+func TestLanguageWithoutAnswers(t *testing.T) {
+	languages := append(mockLanguages(), Language{Code: "de", Name: "german", Repo: utils.WordRepository{
+		Answers: map[string][]string{"4": {}},
+		Allowed: map[string][]string{"4": {"test"}},
+	}})
+	_, err := NewParameters(languages)
+	if err == nil || err.Error() != "german: no answers to play" {
+		t.Fatalf("a language without answers was accepted. err=%v", err)
+	}
+}
+
+func TestLanguagePuzzleCode(t *testing.T) {
+	spanish := mockParameters(NewParameters(mockLanguages()))
+	spanish.setLanguage(1)
+	code := spanish.NextPuzzle()
+	if code.Language != "es" {
+		t.Fatalf("the code does not have the language. got=%q", code.Language)
+	}
+
+	params := mockParameters(NewParameters(mockLanguages()))
+	if err := params.ApplyPuzzleCode(code); err != nil {
+		t.Fatal(err)
+	}
+	if params.Language() != 1 || params.Answers()[0] != "señor" {
+		t.Fatalf("the code did not switch the language. got=%s", params.FieldText(FIELD_LANGUAGE))
+	}
+
+	custom := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests"}})))
+	if err := custom.ApplyPuzzleCode(code); err == nil || err.Error() != `no word list for language "es"` {
+		t.Fatalf("a code in a missing language was accepted. err=%v", err)
+	}
+}
+
+func TestLanguageDefaults(t *testing.T) {
+	params := mockParameters(NewParameters(mockLanguages()))
+	language, wordLen := "Spanish", 4
+	if err := params.ApplyDefaults(config.Defaults{Language: &language, WordLen: &wordLen}); err != nil {
+		t.Fatal(err)
+	}
+	if params.Language() != 1 || params.Fields[FIELD_WORD_LEN].Value != 4 {
+		t.Fatalf("language defaults were not applied. language=%d, length=%d", params.Language(), params.Fields[FIELD_WORD_LEN].Value)
+	}
+	language = "klingon"
+	err := params.ApplyDefaults(config.Defaults{Language: &language})
+	if err == nil || err.Error() != `language must be one of english, spanish. got "klingon"` {
+		t.Fatalf("an unknown language was accepted. err=%v", err)
+	}
+}
+
+func TestFoldAccentsField(t *testing.T) {
+	params := mockParameters(NewParameters([]Language{{Code: "es", Name: "spanish", Repo: utils.WordRepository{
+		Alphabet: "ABCDEFGHIJKLMNÑOPQRSTUVWXYZÓ",
+		Folding:  map[string]string{"Ó": "O"},
+		Answers:  map[string][]string{"5": {"limón"}},
+		Allowed:  map[string][]string{"5": {"limón"}},
+	}}}))
+	gs, err := NewGameSession(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeWord(gs, "limon")
+	if gs.GetState() != engine.VICTORY {
+		t.Fatalf("the plain spelling did not match the accented word. guesses=%v", gs.Guesses())
+	}
+	if params.FieldText(FIELD_FOLD) != "1" {
+		t.Fatalf("unexpected fold accents text. got=%q, expected=%q", params.FieldText(FIELD_FOLD), "1")
+	}
+
+	params.CurEditingIdx = FIELD_FOLD
+	params.IncValAtCurField()
+	if params.Folding() != nil {
+		t.Fatalf("accents are folded after turning it off. got=%q", params.Folding())
+	}
+	gs, err = NewGameSession(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeWord(gs, "limon")
+	if gs.GetState() == engine.VICTORY || gs.GuessesUsed() != 0 {
+		t.Fatalf("the plain spelling was accepted without folding. guesses=%v", gs.Guesses())
+	}
+	gs.ClearCurrentGuess()
+	typeWord(gs, "limón")
+	if gs.GetState() != engine.VICTORY {
+		t.Fatalf("the accented spelling was not accepted. guesses=%v", gs.Guesses())
+	}
+}
+
+func TestFoldAccentsFieldWithoutFolding(t *testing.T) {
+	params := mockParameters(NewParameters(mockLanguages()))
+	if params.FieldText(FIELD_FOLD) != "none" || params.Folding() != nil {
+		t.Fatalf("a language without folding offers it. text=%q, folding=%q", params.FieldText(FIELD_FOLD), params.Folding())
+	}
+}
+
+// mockServer records what a versus game sends
+type mockServer struct {
+	sent []multiplayer.Message
+	err  error
+}
+
+func (m *mockServer) Send(msg multiplayer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *mockServer) lastSent() multiplayer.Message {
+	if len(m.sent) == 0 {
+		return multiplayer.Message{}
+	}
+	return m.sent[len(m.sent)-1]
+}
+
+func mockNewVersus(server *mockServer) *Versus {
+	params := mockParameters(NewDefaultParameters(utils.NewWordRepository(map[string][]string{"5": {"tests", "toast", "crane"}})))
+	params.Fields[FIELD_HINTS].Value = 3
+	v := NewVersus(server, params)
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_WELCOME, Name: "ann", Players: []string{"ann"}})
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_JOINED, Name: "bob"})
+	code := puzzle.Code{Seed: 7, WordLen: 5, NumGuesses: 6, MaxNumFails: 5, NumBoards: 1}
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_START, Round: 1, Code: code.String(), Players: []string{"ann", "bob"}})
+	return v
+}
+
+func TestVersus(t *testing.T) {
+	server := &mockServer{}
+	v := mockNewVersus(server)
+	if server.sent[0].Type != multiplayer.MSG_READY {
+		t.Fatalf("joining did not ask for a round. got=%+v", server.sent[0])
+	}
+	if v.Game == nil || v.Game.HintsLeft != 0 || len(v.Game.Opponents) != 1 || v.Game.Opponents[0].Name != "bob" {
+		t.Fatalf("the round was not started against bob without hints. got=%+v", v.Game)
+	}
+
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_SCORED, Name: "bob", Row: "gy..."})
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_SCORED, Name: "ann", Row: "ggggg"})
+	bob := v.Game.Opponents[0]
+	if len(bob.Rows) != 1 || bob.Rows[0][1] != engine.PARTIAL {
+		t.Fatalf("the row of bob was not recorded. got=%v", bob.Rows)
+	}
+
+	target := strings.ToLower(v.Game.Target())
+	other := "tests"
+	if target == other {
+		other = "toast"
+	}
+	for _, r := range other {
+		v.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	v.HandleEventKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if msg := server.lastSent(); msg.Type != multiplayer.MSG_GUESS || msg.Word != strings.ToUpper(other) {
+		t.Fatalf("the guess was not sent. got=%+v", msg)
+	}
+	if v.HandleEventKey(tcell.NewEventKey(tcell.KeyCtrlZ, 0, tcell.ModCtrl)) || v.Game.Suspended {
+		t.Fatal("a versus game was suspended")
+	}
+
+	typeWord(v.Game, target)
+	v.report(1)
+	if msg := server.lastSent(); msg.Type != multiplayer.MSG_GUESS || msg.Word != strings.ToUpper(target) {
+		t.Fatalf("the winning guess was not sent. got=%+v", msg)
+	}
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_FINISHED, Name: "bob", Won: false})
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_OVER, Winner: "ann", Target: v.Game.Target()})
+	if bob.State != engine.LOSS || !strings.HasPrefix(v.Game.HelpText, "You won the round!") {
+		t.Fatalf("the end of the round was not reported. state=%v, help=%q", bob.State, v.Game.HelpText)
+	}
+
+	v.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if msg := server.lastSent(); msg.Type != multiplayer.MSG_READY || !v.Ready {
+		t.Fatalf("continuing did not ask for the next round. got=%+v", msg)
+	}
+	if !v.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone)) {
+		t.Fatal("going back did not leave")
+	}
+}
+
+func TestVersusGiveUpAndDisconnect(t *testing.T) {
+	server := &mockServer{}
+	v := mockNewVersus(server)
+	v.HandleEventKey(tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl))
+	if msg := server.lastSent(); msg.Type != multiplayer.MSG_GIVE_UP {
+		t.Fatalf("giving up was not sent. got=%+v", msg)
+	}
+
+	v.HandleMessage(multiplayer.Message{Type: multiplayer.MSG_LEFT, Name: "bob"})
+	if !v.Game.Opponents[0].Left || slices.Contains(v.Players, "bob") {
+		t.Fatalf("bob did not leave. players=%v", v.Players)
+	}
+
+	sent := len(server.sent)
+	v.Disconnect(fmt.Errorf("EOF"))
+	v.HandleEventKey(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
+	if len(server.sent) != sent || !strings.HasPrefix(v.Game.HelpText, "Not connected") {
+		t.Fatalf("a message was sent without a server. help=%q", v.Game.HelpText)
 	}
 }
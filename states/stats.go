@@ -0,0 +1,43 @@
+package states
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/stats"
+)
+
+// StatsScreen pages through the summaries of past games.
+type StatsScreen struct {
+	Summaries     []stats.Summary
+	CurViewingIdx int
+	Err           error // set when the history could not be read
+	Keymap        Keymap
+}
+
+func NewStatsScreen(store *stats.Store, keymap Keymap) *StatsScreen {
+	records, err := store.Load()
+	return &StatsScreen{
+		Summaries: stats.Breakdown(records),
+		Err:       err,
+		Keymap:    keymap,
+	}
+}
+
+func (ss *StatsScreen) Current() stats.Summary {
+	return ss.Summaries[ss.CurViewingIdx]
+}
+
+func (ss *StatsScreen) HandleEventKey(ev *tcell.EventKey) bool {
+	switch ss.Keymap.Action(CONTEXT_STATS, ev) {
+	case ACTION_NAV_LEFT:
+		ss.CurViewingIdx -= 1
+		if ss.CurViewingIdx < 0 {
+			ss.CurViewingIdx = len(ss.Summaries) - 1
+		}
+	case ACTION_NAV_RIGHT:
+		ss.CurViewingIdx += 1
+		ss.CurViewingIdx %= len(ss.Summaries)
+	case ACTION_STATS, ACTION_BACK:
+		return true
+	}
+	return false
+}
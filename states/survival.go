@@ -0,0 +1,61 @@
+package states
+
+import (
+	"fmt"
+
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+// SURVIVAL_PAR is the number of guesses a word of a survival run is expected to take.
+// Solving it in fewer earns the difference as bonus guesses.
+const SURVIVAL_PAR int = 4
+
+func (gs *GameSession) IsSurvival() bool {
+	return gs.Parameters.Mode() == MODE_SURVIVAL
+}
+
+// survivalBonus is what solving the current word adds to the pool of guesses.
+func (gs *GameSession) survivalBonus() int {
+	return max(SURVIVAL_PAR-gs.GuessesUsed(), 0)
+}
+
+// survivalPool is the guesses carried over to the next word. It never goes over MAX_GUESSES.
+func (gs *GameSession) survivalPool() int {
+	return min(gs.GuessesLeft()+gs.survivalBonus(), MAX_GUESSES)
+}
+
+// canContinueRun reports whether a survival run goes on to another word.
+func (gs *GameSession) canContinueRun() bool {
+	return gs.IsSurvival() && gs.GetState() == engine.VICTORY && gs.survivalPool() > 0
+}
+
+// survivalText reports how a survival run goes on once a word has ended, or the final score
+// when it does not.
+func (gs *GameSession) survivalText() string {
+	if !gs.IsSurvival() || gs.GetState() == engine.ACTIVE {
+		return ""
+	}
+	if !gs.canContinueRun() {
+		return fmt.Sprintf("Run over! %s. ", gs.runSummary())
+	}
+	bonus := gs.survivalPool() - gs.GuessesLeft()
+	return fmt.Sprintf("+%d bonus, %d guesses for the next word. ", bonus, gs.survivalPool())
+}
+
+// continueRun plays the next word of a survival run with the guesses and failed entries left.
+// The run is left as it is when the next word can not be started.
+func (gs *GameSession) continueRun() error {
+	pool := gs.survivalPool()
+	code := gs.Parameters.NextPuzzle()
+	cfg, err := code.Config(gs.Parameters.Answers(), gs.Parameters.ValidWords())
+	if err != nil {
+		return err
+	}
+	if err := gs.GameSession.Continue(cfg.Targets, pool-gs.GuessesLeft()); err != nil {
+		return err
+	}
+	gs.Puzzle = code
+	gs.startClock()
+	gs.HelpText = fmt.Sprintf("Word %d. %s so far", len(gs.WordTimes)+1, gs.runSummary())
+	return nil
+}
@@ -0,0 +1,97 @@
+package states
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+)
+
+// EventTick is posted to the screen while a timed game is played, so the clock is redrawn
+// and time runs out without waiting for a key press.
+type EventTick struct {
+	tcell.EventTime
+}
+
+func NewEventTick() *EventTick {
+	ev := &EventTick{}
+	ev.SetEventNow()
+	return ev
+}
+
+func (gs *GameSession) IsTimed() bool {
+	return gs.Parameters.IsTimed()
+}
+
+// Deadline is when the time runs out. A countdown is per word, a speedrun is for the whole run.
+func (gs *GameSession) Deadline() time.Time {
+	if gs.Parameters.Mode() == MODE_SPEEDRUN {
+		return gs.RunStarted.Add(gs.Parameters.TimeLimit())
+	}
+	return gs.Started.Add(gs.Parameters.TimeLimit())
+}
+
+// Remaining is the time left at now. It never goes below zero and stops once the game has ended.
+func (gs *GameSession) Remaining(now time.Time) time.Duration {
+	if !gs.stoppedAt.IsZero() {
+		now = gs.stoppedAt
+	}
+	return max(gs.Deadline().Sub(now), 0)
+}
+
+// startClock starts timing a new word.
+func (gs *GameSession) startClock() {
+	gs.Started = time.Now()
+	gs.stoppedAt = time.Time{}
+}
+
+// stopClock freezes the clock once the game has ended.
+func (gs *GameSession) stopClock(now time.Time) {
+	if gs.GetState() != engine.ACTIVE && gs.stoppedAt.IsZero() {
+		gs.stoppedAt = now
+	}
+}
+
+// Tick ends the game once its time has run out.
+func (gs *GameSession) Tick(now time.Time) {
+	if !gs.IsTimed() || gs.GetState() != engine.ACTIVE || now.Before(gs.Deadline()) {
+		return
+	}
+	gs.TimeOut()
+	gs.stopClock(now)
+	if gs.Parameters.Mode() == MODE_SPEEDRUN {
+		gs.HelpText = fmt.Sprintf("Time's up! %s. %s", gs.runSummary(), gs.targetsText()) + gs.gameOverPrompt()
+		return
+	}
+	gs.HelpText = "Time's up! " + gs.targetsText() + gs.gameOverPrompt()
+}
+
+// Advance moves a speedrun on to its next word once the current one is solved, keeping the
+// clock running. A word that is lost or given up ends the run. It reports whether it moved on,
+// showing why not when the next word could not start.
+func (gs *GameSession) Advance() bool {
+	if gs.Parameters.Mode() != MODE_SPEEDRUN || gs.GetState() != engine.VICTORY {
+		return false
+	}
+	message := fmt.Sprintf("%s is correct! ", gs.Guesses()[len(gs.Guesses())-1])
+	if err := gs.nextWord(); err != nil {
+		gs.HelpText = message + "Could not start the next word: " + err.Error() + ". " + gs.gameOverPrompt()
+		return false
+	}
+	gs.HelpText = message + gs.runSummary()
+	return true
+}
+
+// runSummary is how a run is going.
+func (gs *GameSession) runSummary() string {
+	if len(gs.WordTimes) == 0 {
+		return "0 solved"
+	}
+	var total time.Duration
+	for _, t := range gs.WordTimes {
+		total += t
+	}
+	average := (total / time.Duration(len(gs.WordTimes))).Round(time.Second)
+	return fmt.Sprintf("%d solved, %s per word", len(gs.WordTimes), average)
+}
@@ -0,0 +1,277 @@
+package states
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"gitlab.com/daneofmanythings/wohrdle/engine"
+	"gitlab.com/daneofmanythings/wohrdle/multiplayer"
+	"gitlab.com/daneofmanythings/wohrdle/puzzle"
+	"gitlab.com/daneofmanythings/wohrdle/solver"
+)
+
+// EventMessage is posted to the screen for every message from the server of a versus game.
+// Err is set instead once the connection is lost.
+type EventMessage struct {
+	tcell.EventTime
+	Msg multiplayer.Message
+	Err error
+}
+
+func NewEventMessage(msg multiplayer.Message, err error) *EventMessage {
+	ev := &EventMessage{Msg: msg, Err: err}
+	ev.SetEventNow()
+	return ev
+}
+
+// Sender sends messages to the server of a versus game. see multiplayer.Conn
+type Sender interface {
+	Send(msg multiplayer.Message) error
+}
+
+// Opponent is another player of a versus round. Only the colors of their rows are ever seen.
+type Opponent struct {
+	Name  string
+	Rows  [][]engine.CellState
+	State engine.GameState // ACTIVE until they are done with the round
+	Left  bool             // they disconnected
+}
+
+// Versus is a race against other players to the word a server picks. Every round is a new
+// GameSession built from the puzzle code the server starts it with.
+type Versus struct {
+	Game       *GameSession // nil until the first round starts
+	Parameters *Parameters
+	Name       string   // ours, as the server knows it
+	Players    []string // everyone connected
+	Round      int
+	Message    string // shown while waiting for the first round
+
+	Ready        bool // waiting on the others for the next round
+	Disconnected bool
+
+	server Sender
+}
+
+// NewVersus plays against the players of server. Rounds are always classic games without hints.
+func NewVersus(server Sender, params *Parameters) *Versus {
+	params.Fields[FIELD_DAILY].Value = FALSE
+	params.Fields[FIELD_HINTS].Value = 0
+	params.Fields[FIELD_MODE].Value = MODE_CLASSIC
+	return &Versus{
+		Parameters: params,
+		Message:    "Connecting...",
+		server:     server,
+	}
+}
+
+// HandleMessage acts on a message from the server.
+func (v *Versus) HandleMessage(msg multiplayer.Message) {
+	switch msg.Type {
+	case multiplayer.MSG_WELCOME:
+		v.Name = msg.Name
+		v.Players = msg.Players
+		v.Message = "Waiting for players..."
+		v.ready()
+	case multiplayer.MSG_JOINED:
+		v.Players = append(v.Players, msg.Name)
+		v.notify(msg.Name + " joined.")
+	case multiplayer.MSG_LEFT:
+		v.Players = slices.DeleteFunc(v.Players, func(name string) bool { return name == msg.Name })
+		if opp := v.opponent(msg.Name); opp != nil {
+			opp.Left = true
+		}
+		v.notify(msg.Name + " left.")
+	case multiplayer.MSG_START:
+		v.start(msg)
+	case multiplayer.MSG_SCORED:
+		if opp := v.opponent(msg.Name); opp != nil {
+			if row, err := solver.ParseFeedback(msg.Row); err == nil {
+				opp.Rows = append(opp.Rows, row)
+			}
+		}
+	case multiplayer.MSG_FINISHED:
+		opp := v.opponent(msg.Name)
+		if opp == nil {
+			return
+		}
+		opp.State = engine.LOSS
+		if msg.Won {
+			opp.State = engine.VICTORY
+			if v.Game.GetState() == engine.ACTIVE {
+				v.notify(fmt.Sprintf("%s solved it in %d!", msg.Name, msg.Guesses))
+			}
+		}
+	case multiplayer.MSG_OVER:
+		v.over(msg)
+	case multiplayer.MSG_REJECTED:
+		v.notify(fmt.Sprintf("The server did not take %s: %s.", msg.Word, msg.Error))
+	case multiplayer.MSG_ERROR:
+		v.notify(msg.Error)
+	}
+}
+
+// Disconnect reports that the server is gone. A round being played can still be finished.
+func (v *Versus) Disconnect(err error) {
+	if v.Disconnected {
+		return
+	}
+	v.Disconnected = true
+	v.notify("Lost the connection to the server: " + err.Error() + ".")
+}
+
+// start plays the round of the puzzle the server picked.
+func (v *Versus) start(msg multiplayer.Message) {
+	code, err := puzzle.Parse(msg.Code)
+	if err == nil {
+		err = v.Parameters.ApplyPuzzleCode(code)
+	}
+	var gs *GameSession
+	if err == nil {
+		gs, err = NewGameSession(v.Parameters)
+	}
+	if err != nil {
+		// the round is given up so it does not wait on us
+		v.send(multiplayer.Message{Type: multiplayer.MSG_GIVE_UP})
+		v.notify("Could not play the round: " + err.Error())
+		return
+	}
+
+	for _, name := range msg.Players {
+		if name != v.Name {
+			gs.Opponents = append(gs.Opponents, &Opponent{Name: name})
+		}
+	}
+	gs.HelpText = fmt.Sprintf("Round %d. The first to solve it wins!", msg.Round)
+	v.Game = gs
+	v.Round = msg.Round
+	v.Ready = false
+}
+
+// over reports who won the round.
+func (v *Versus) over(msg multiplayer.Message) {
+	if v.Game == nil {
+		return
+	}
+	result := "Nobody solved it."
+	switch msg.Winner {
+	case "":
+	case v.Name:
+		result = "You won the round!"
+	default:
+		result = msg.Winner + " won the round!"
+	}
+	if v.Ready {
+		result += " Waiting for the others to be ready..."
+	}
+	v.notify(fmt.Sprintf("%s %s was the word.", result, msg.Target))
+}
+
+// HandleEventKey plays the round. It reports true when the player leaves.
+func (v *Versus) HandleEventKey(ev *tcell.EventKey) bool {
+	km := v.Parameters.Keymap
+	gs := v.Game
+	if gs == nil {
+		return km.Action(CONTEXT_GAME_OVER, ev) == ACTION_BACK
+	}
+	if gs.pasting || gs.ShowingKeys {
+		return gs.HandleEventKey(ev)
+	}
+
+	if gs.GetState() == engine.ACTIVE {
+		if km.Action(CONTEXT_GAME, ev) == ACTION_SUSPEND {
+			gs.HelpText = "A versus game can not be saved for later."
+			return false
+		}
+		used := gs.GuessesUsed()
+		gs.HandleEventKey(ev)
+		v.report(used)
+		return false
+	}
+
+	switch km.Action(CONTEXT_GAME_OVER, ev) {
+	case ACTION_CONTINUE:
+		v.ready()
+		return false
+	case ACTION_BACK:
+		return true
+	}
+	return gs.HandleEventKey(ev)
+}
+
+// HandleEventMouse types the letter of a clicked key, see GameSession.HandleEventMouse.
+func (v *Versus) HandleEventMouse(ev *tcell.EventMouse, letter rune) {
+	if v.Game != nil {
+		v.Game.HandleEventMouse(ev, letter)
+	}
+}
+
+// HandleEventPaste types the pasted letters, see GameSession.HandleEventPaste.
+func (v *Versus) HandleEventPaste(ev *tcell.EventPaste) {
+	if v.Game != nil {
+		v.Game.HandleEventPaste(ev)
+	}
+}
+
+// report tells the server about a guess scored since used guesses, or about losing without one.
+func (v *Versus) report(used int) {
+	gs := v.Game
+	if gs.GuessesUsed() > used {
+		guesses := gs.Guesses()
+		v.send(multiplayer.Message{Type: multiplayer.MSG_GUESS, Word: guesses[len(guesses)-1]})
+	} else if gs.GetState() != engine.ACTIVE {
+		v.send(multiplayer.Message{Type: multiplayer.MSG_GIVE_UP})
+	}
+}
+
+// ready asks for the next round.
+func (v *Versus) ready() {
+	if v.Disconnected {
+		v.notify("Not connected to the server.")
+		return
+	}
+	if v.Ready {
+		return
+	}
+	v.Ready = true
+	v.send(multiplayer.Message{Type: multiplayer.MSG_READY})
+	if v.Game != nil {
+		v.notify("Ready for the next round. Waiting for the others...")
+	}
+}
+
+func (v *Versus) send(msg multiplayer.Message) {
+	if v.Disconnected {
+		return
+	}
+	if err := v.server.Send(msg); err != nil {
+		v.Disconnect(err)
+	}
+}
+
+// notify shows text below the game, keeping the keys of the game over screen in view, or in
+// the lobby before the first round.
+func (v *Versus) notify(text string) {
+	gs := v.Game
+	if gs == nil {
+		v.Message = text
+		return
+	}
+	gs.HelpText = text
+	if gs.GetState() != engine.ACTIVE {
+		gs.HelpText += " " + v.Parameters.Keymap.Summary(CONTEXT_GAME_OVER, ACTION_CONTINUE, ACTION_SHARE, ACTION_BACK)
+	}
+}
+
+func (v *Versus) opponent(name string) *Opponent {
+	if v.Game == nil {
+		return nil
+	}
+	for _, opp := range v.Game.Opponents {
+		if opp.Name == name {
+			return opp
+		}
+	}
+	return nil
+}
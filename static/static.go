@@ -1,131 +1,43 @@
 package static
 
 import (
-	"bufio"
 	_ "embed"
-	"encoding/json"
-	"log"
-	"os"
-	"strconv"
-	"unicode"
-
-	"gitlab.com/daneofmanythings/wohrdle/utils"
 )
 
+// words.json is built from words.txt, and targets are only drawn from the common words of
+// answers.txt:
+//
+//	wohrdle wordlist build -in words.txt -alphabet ABCDEFGHIJKLMNOPQRSTUVWXYZ -answers answers.txt -out words.json
+//
 //go:embed "words.json"
 var WordRepoBytes []byte
 
-// WARN: The rest of this module is only used to clean the linux word list.
-
-// TODO: fix this. It is hella error prone
-const (
-	targetRawWordListAbsPath string = "/etc/dictionaries-common/words" // for linux
-	localWordTxt             string = "./static/words.txt"             // relative to the root
-	localWordJSON            string = "./static/words.json"
+// the word lists of other languages are built from the .txt file of the same name, giving the
+// letters of the language and the accents that can be typed as plain letters:
+//
+//	wohrdle wordlist build -in es.txt -alphabet ABCDEFGHIJKLMNÑOPQRSTUVWXYZÁÉÍÓÚÜ -fold áa,ée,íi,óo,úu,üu -out es.json
+//	wohrdle wordlist build -in de.txt -alphabet ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÜß -fold äa,öo,üu -out de.json
+//	wohrdle wordlist build -in pt.txt -alphabet ABCDEFGHIJKLMNOPQRSTUVWXYZÇÁÀÂÃÉÊÍÓÔÕÚ -fold àa,áa,âa,ãa,çc,ée,êe,íi,óo,ôo,õo,úu -out pt.json
+var (
+	//go:embed "es.json"
+	spanishBytes []byte
+	//go:embed "de.json"
+	germanBytes []byte
+	//go:embed "pt.json"
+	portugueseBytes []byte
 )
 
-func check(e error) {
-	if e != nil {
-		panic(e)
-	}
-}
-
-func MakeWordFile() {
-	file, err := os.Open(targetRawWordListAbsPath)
-	check(err)
-	defer file.Close()
-
-	var words string
-	var intermediate string
-
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		intermediate = ""
-		intermediate += scanner.Text()
-		for _, r := range intermediate {
-			if unicode.IsUpper(r) || r == '\'' || !utils.RuneIsAlpha(r) {
-				intermediate = ""
-				break
-			}
-		}
-		if intermediate != "" {
-			intermediate += "\n"
-		}
-		words += intermediate
-	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
-	}
-
-	f, err2 := os.Create(localWordTxt)
-	check(err2)
-	defer f.Close()
-
-	f.WriteString(words)
-	f.Sync()
-}
-
-func CleanWordFile() {
-	file, err := os.Open(targetRawWordListAbsPath)
-	check(err)
-	defer file.Close()
-
-	var words string
-	var intermediate string
-
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		intermediate = ""
-		intermediate += scanner.Text()
-		for _, r := range intermediate {
-			if unicode.IsUpper(r) {
-				intermediate = ""
-			} else if r == '\'' {
-				intermediate = ""
-			}
-		}
-		words += intermediate
-		words += "\n"
-	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
-	}
-
-	f, err2 := os.Create(localWordTxt)
-	check(err2)
-	defer f.Close()
-
-	f.WriteString(words)
-	f.Sync()
+// Language is an embedded word list.
+type Language struct {
+	Code  string // ISO 639-1
+	Name  string
+	Bytes []byte
 }
 
-func CreateJSONFile() {
-	wordRepo := utils.WordRepository{
-		Words: map[string][]string{},
-	}
-
-	file, err := os.Open(localWordTxt)
-	check(err)
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		word := scanner.Text()
-		wordLen := len(word)
-		wordLenStr := strconv.Itoa(wordLen)
-		wordRepo.Words[wordLenStr] = append(wordRepo.Words[wordLenStr], word)
-	}
-
-	b, e := json.MarshalIndent(wordRepo, "", "\t")
-	check(e)
-
-	f, err2 := os.Create(localWordJSON)
-	check(err2)
-
-	f.Write(b)
+// Languages are the embedded word lists. The first one is the default.
+var Languages []Language = []Language{
+	{"en", "english", WordRepoBytes},
+	{"es", "spanish", spanishBytes},
+	{"de", "german", germanBytes},
+	{"pt", "portuguese", portugueseBytes},
 }
@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const fileName string = "stats.jsonl"
+
+// Record is a finished game.
+type Record struct {
+	Finished    time.Time     `json:"finished"`
+	Duration    time.Duration `json:"duration"`
+	Puzzle      string        `json:"puzzle"`
+	WordLen     int           `json:"word_len"`
+	NumGuesses  int           `json:"num_guesses"`
+	MaxNumFails int           `json:"max_num_fails"`
+	HardMode    bool          `json:"hard_mode"`
+	Daily       bool          `json:"daily"`
+	Absurd      bool          `json:"absurd,omitempty"`
+	Target      string        `json:"target"`
+	Guesses     []string      `json:"guesses"`
+	FailsUsed   int           `json:"fails_used"`
+	HintsUsed   int           `json:"hints_used,omitempty"`
+	Mode        string        `json:"mode,omitempty"` // blank for classic games
+	TimedOut    bool          `json:"timed_out,omitempty"`
+	Won         bool          `json:"won"`
+}
+
+// Store appends records to a JSON lines file so a crash can at most lose the game being written.
+type Store struct {
+	path string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{path: filepath.Join(dir, fileName)}
+}
+
+func (st *Store) Append(rec Record) error {
+	if err := os.MkdirAll(filepath.Dir(st.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(st.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Load reads every record. A missing file is an empty history.
+func (st *Store) Load() ([]Record, error) {
+	f, err := os.Open(st.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		rec := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", st.path, line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// Summary is the statistics over a set of records.
+type Summary struct {
+	Title        string
+	Played       int
+	Wins         int
+	CurStreak    int
+	MaxStreak    int
+	Distribution []int // Distribution[i] is the number of wins in i+1 guesses
+}
+
+func (s Summary) WinPercent() int {
+	if s.Played == 0 {
+		return 0
+	}
+	return s.Wins * 100 / s.Played
+}
+
+func (s Summary) Losses() int {
+	return s.Played - s.Wins
+}
+
+// Summarize computes the summary of records in the order they were finished.
+func Summarize(title string, records []Record) Summary {
+	sorted := slices.Clone(records)
+	slices.SortStableFunc(sorted, func(a, b Record) int {
+		return a.Finished.Compare(b.Finished)
+	})
+
+	s := Summary{Title: title}
+	for _, rec := range sorted {
+		s.Played++
+		if !rec.Won {
+			s.CurStreak = 0
+			continue
+		}
+		s.Wins++
+		s.CurStreak++
+		s.MaxStreak = max(s.MaxStreak, s.CurStreak)
+		// a malformed win without guesses, e.g. from an edited file, still counts as a win
+		if len(rec.Guesses) == 0 {
+			continue
+		}
+		for len(s.Distribution) < len(rec.Guesses) {
+			s.Distribution = append(s.Distribution, 0)
+		}
+		s.Distribution[len(rec.Guesses)-1]++
+	}
+	return s
+}
+
+// Breakdown summarizes everything, followed by each word length and hard-mode setting
+// that has been played, shortest words first.
+func Breakdown(records []Record) []Summary {
+	type key struct {
+		wordLen  int
+		hardMode bool
+	}
+	groups := map[key][]Record{}
+	keys := []key{}
+	for _, rec := range records {
+		k := key{rec.WordLen, rec.HardMode}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], rec)
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		if a.wordLen != b.wordLen {
+			return a.wordLen - b.wordLen
+		}
+		if a.hardMode == b.hardMode {
+			return 0
+		}
+		if a.hardMode {
+			return 1
+		}
+		return -1
+	})
+
+	summaries := []Summary{Summarize("all games", records)}
+	for _, k := range keys {
+		title := fmt.Sprintf("%d letters", k.wordLen)
+		if k.hardMode {
+			title += ", hard-mode"
+		}
+		summaries = append(summaries, Summarize(title, groups[k]))
+	}
+	return summaries
+}
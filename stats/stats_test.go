@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func mockRecord(day int, wordLen int, hardMode bool, guesses int, won bool) Record {
+	return Record{
+		Finished: time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC),
+		WordLen:  wordLen,
+		HardMode: hardMode,
+		Guesses:  make([]string, guesses),
+		Won:      won,
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	st := NewStore(t.TempDir())
+
+	records, err := st.Load()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("a missing store was not empty. records=%v, err=%v", records, err)
+	}
+
+	rec := mockRecord(1, 5, false, 3, true)
+	rec.Target = "TESTS"
+	rec.Guesses = []string{"TOAST", "ADIEU", "TESTS"}
+	for i := 0; i < 2; i++ {
+		if err := st.Append(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err = st.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].Target != "TESTS" || len(records[1].Guesses) != 3 {
+		t.Fatalf("unexpected records. got=%+v", records)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	// out of order on purpose. streaks follow the finish time
+	records := []Record{
+		mockRecord(4, 5, false, 2, true),
+		mockRecord(1, 5, false, 3, true),
+		mockRecord(2, 5, false, 3, true),
+		mockRecord(3, 5, false, 6, false),
+		mockRecord(5, 5, false, 3, true),
+	}
+
+	s := Summarize("test", records)
+	if s.Played != 5 || s.Wins != 4 || s.WinPercent() != 80 {
+		t.Fatalf("unexpected totals. got=%+v", s)
+	}
+	if s.CurStreak != 2 || s.MaxStreak != 2 {
+		t.Fatalf("unexpected streaks. cur=%d, max=%d", s.CurStreak, s.MaxStreak)
+	}
+	expected := []int{0, 1, 3}
+	for i := range expected {
+		if s.Distribution[i] != expected[i] {
+			t.Fatalf("unexpected distribution. got=%v, expected=%v", s.Distribution, expected)
+		}
+	}
+}
+
+func TestSummarizeWinWithoutGuesses(t *testing.T) {
+	records := []Record{
+		mockRecord(1, 5, false, 0, true),
+		mockRecord(2, 5, false, 2, true),
+	}
+
+	s := Summarize("test", records)
+	if s.Played != 2 || s.Wins != 2 || s.CurStreak != 2 {
+		t.Fatalf("the malformed win was not counted. got=%+v", s)
+	}
+	if len(s.Distribution) != 2 || s.Distribution[0] != 0 || s.Distribution[1] != 1 {
+		t.Fatalf("unexpected distribution. got=%v, expected=%v", s.Distribution, []int{0, 1})
+	}
+}
+
+func TestBreakdown(t *testing.T) {
+	records := []Record{
+		mockRecord(1, 6, false, 3, true),
+		mockRecord(2, 5, true, 3, true),
+		mockRecord(3, 5, false, 3, false),
+		mockRecord(4, 5, false, 3, true),
+	}
+
+	summaries := Breakdown(records)
+	expected := []struct {
+		title  string
+		played int
+	}{
+		{"all games", 4},
+		{"5 letters", 2},
+		{"5 letters, hard-mode", 1},
+		{"6 letters", 1},
+	}
+	if len(summaries) != len(expected) {
+		t.Fatalf("unexpected number of summaries. got=%d, expected=%d", len(summaries), len(expected))
+	}
+	for i := range expected {
+		if summaries[i].Title != expected[i].title || summaries[i].Played != expected[i].played {
+			t.Fatalf("unexpected summary at %d. got=%+v", i, summaries[i])
+		}
+	}
+}
@@ -1,24 +1,111 @@
 package utils
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"unicode"
 )
 
+// DEFAULT_ALPHABET is the alphabet of a word list that does not give one.
+const DEFAULT_ALPHABET string = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// WordRepository holds the words for each length. The keys are the len of the contained words.
+// Targets are only drawn from Answers, while any word in Allowed is a valid guess.
 type WordRepository struct {
-	Words map[string][]string // the keys are the len of the contained words
+	// the upper-case letters the words are spelled with, in the order they are listed. when
+	// missing it is DEFAULT_ALPHABET followed by any other letters of the words
+	Alphabet string `json:",omitempty"`
+	// the plain letter each accented letter of the alphabet can be typed as, such as "É": "E"
+	Folding map[string]string `json:",omitempty"`
+
+	Words   map[string][]string `json:",omitempty"` // legacy shape. every word is both an answer and allowed
+	Answers map[string][]string `json:",omitempty"`
+	Allowed map[string][]string `json:",omitempty"`
 }
 
-func Find[T comparable](s []T, t T) int {
-	for i := range s {
-		if s[i] == t {
-			return i
+// NewWordRepository makes a repository where every word is both an answer and allowed.
+func NewWordRepository(words map[string][]string) WordRepository {
+	wr := WordRepository{Words: words}
+	wr.normalize()
+	return wr
+}
+
+// normalize fills in whichever pools are missing so that Answers and Allowed are always
+// populated and every answer is also allowed.
+func (wr *WordRepository) normalize() {
+	if wr.Answers == nil && wr.Allowed == nil {
+		wr.Answers = wr.Words
+		wr.Allowed = wr.Words
+	} else if wr.Answers == nil {
+		wr.Answers = wr.Allowed
+	} else if wr.Allowed == nil {
+		wr.Allowed = wr.Answers
+	}
+
+	merged := map[string][]string{}
+	for length, words := range wr.Allowed {
+		merged[length] = words
+	}
+	for length, answers := range wr.Answers {
+		allowed := merged[length]
+		seen := make(map[string]struct{}, len(allowed))
+		for _, word := range allowed {
+			seen[word] = struct{}{}
 		}
+		for _, answer := range answers {
+			if _, ok := seen[answer]; !ok {
+				seen[answer] = struct{}{}
+				allowed = append(allowed, answer)
+			}
+		}
+		merged[length] = allowed
 	}
-	return len(s)
+	wr.Allowed = merged
+
+	if wr.Alphabet == "" {
+		wr.Alphabet = DEFAULT_ALPHABET
+		extra := []rune{}
+		seen := map[rune]struct{}{}
+		for _, r := range wr.Alphabet {
+			seen[r] = struct{}{}
+		}
+		for _, words := range wr.Allowed {
+			for _, word := range words {
+				for _, r := range strings.ToUpper(word) {
+					if _, ok := seen[r]; !ok {
+						seen[r] = struct{}{}
+						extra = append(extra, r)
+					}
+				}
+			}
+		}
+		slices.Sort(extra)
+		wr.Alphabet += string(extra)
+	}
+}
+
+// Letters are the runes of the alphabet.
+func (wr WordRepository) Letters() []rune {
+	return []rune(wr.Alphabet)
+}
+
+// FoldingTable is Folding by rune. nil when the words have no accents to fold.
+func (wr WordRepository) FoldingTable() map[rune]rune {
+	if len(wr.Folding) == 0 {
+		return nil
+	}
+	table := map[rune]rune{}
+	for from, to := range wr.Folding {
+		table[[]rune(from)[0]] = []rune(to)[0]
+	}
+	return table
 }
 
 func RuneIsAlpha(r rune) bool {
@@ -42,16 +129,159 @@ func LoadWordRepoFromJSON(path string) (WordRepository, error) {
 	if err != nil {
 		return WordRepository{}, err
 	}
+	defer file.Close()
 
 	wr := WordRepository{}
 
-	byteVal, _ := io.ReadAll(file)
-
-	json.Unmarshal(byteVal, &wr)
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&wr); err != nil {
+		return WordRepository{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := wr.validate(); err != nil {
+		return WordRepository{}, fmt.Errorf("%s: %w", path, err)
+	}
+	wr.normalize()
 
 	return wr, nil
 }
 
+// LoadWordRepoFromText reads a newline-delimited word list and buckets it by length.
+// Blank lines and lines starting with '#' are skipped.
+func LoadWordRepoFromText(path string) (WordRepository, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return WordRepository{}, err
+	}
+	defer file.Close()
+
+	words := map[string][]string{}
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		word = strings.ToLower(word)
+		if err := validateWord(word); err != nil {
+			return WordRepository{}, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		length := strconv.Itoa(len([]rune(word)))
+		words[length] = append(words[length], word)
+	}
+	if err := scanner.Err(); err != nil {
+		return WordRepository{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(words) == 0 {
+		return WordRepository{}, fmt.Errorf("%s: no words found", path)
+	}
+
+	return NewWordRepository(words), nil
+}
+
+// LoadWordRepoFromFile loads a JSON repository or a plain text word list, going by the
+// file extension.
+func LoadWordRepoFromFile(path string) (WordRepository, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return LoadWordRepoFromJSON(path)
+	}
+	return LoadWordRepoFromText(path)
+}
+
+// validate checks that every pool is keyed by the length of the words it holds and
+// that the words can be typed.
+func (wr *WordRepository) validate() error {
+	pools := []struct {
+		name  string
+		words map[string][]string
+	}{
+		{"Words", wr.Words},
+		{"Answers", wr.Answers},
+		{"Allowed", wr.Allowed},
+	}
+	alphabet := []rune(wr.Alphabet)
+	for _, r := range alphabet {
+		if !unicode.IsLetter(r) || unicode.ToUpper(r) != r {
+			return fmt.Errorf("Alphabet: %q is not an upper-case letter", r)
+		}
+	}
+	for from, to := range wr.Folding {
+		for _, letter := range []string{from, to} {
+			r := []rune(letter)
+			if len(r) != 1 || !unicode.IsLetter(r[0]) || unicode.ToUpper(r[0]) != r[0] {
+				return fmt.Errorf("Folding: %q is not an upper-case letter", letter)
+			}
+			if len(alphabet) > 0 && !slices.Contains(alphabet, r[0]) {
+				return fmt.Errorf("Folding: %q is not in the alphabet", letter)
+			}
+		}
+		if from == to {
+			return fmt.Errorf("Folding: %q folds to itself", from)
+		}
+		if _, ok := wr.Folding[to]; ok {
+			return fmt.Errorf("Folding: %q folds to %q, which is folded too", from, to)
+		}
+	}
+	count := 0
+	for _, pool := range pools {
+		for key, words := range pool.words {
+			length, err := strconv.Atoi(key)
+			if err != nil || length < 1 {
+				return fmt.Errorf("%s: key %q is not a word length", pool.name, key)
+			}
+			for i, word := range words {
+				if err := validateWord(word); err != nil {
+					return fmt.Errorf("%s[%q][%d]: %w", pool.name, key, i, err)
+				}
+				if len([]rune(word)) != length {
+					return fmt.Errorf("%s[%q][%d]: %q has length %d", pool.name, key, i, word, len([]rune(word)))
+				}
+				if word != strings.ToLower(word) {
+					return fmt.Errorf("%s[%q][%d]: %q must be lower-case", pool.name, key, i, word)
+				}
+				if len(alphabet) > 0 {
+					for _, r := range strings.ToUpper(word) {
+						if !slices.Contains(alphabet, r) {
+							return fmt.Errorf("%s[%q][%d]: %q contains %q, which is not in the alphabet", pool.name, key, i, word, r)
+						}
+					}
+				}
+			}
+			count += len(words)
+		}
+	}
+	if count == 0 {
+		return errors.New("no words found")
+	}
+	// a list with its own answers must have some, or there is nothing to guess
+	if wr.Answers != nil {
+		answers := 0
+		for _, words := range wr.Answers {
+			answers += len(words)
+		}
+		if answers == 0 {
+			return errors.New("Answers: no words found")
+		}
+	}
+	return nil
+}
+
+func validateWord(word string) error {
+	for _, r := range word {
+		if !unicode.IsLetter(r) || !RuneIsAlpha(r) {
+			return fmt.Errorf("%q contains %q, which is not a letter", word, r)
+		}
+	}
+	return nil
+}
+
 func LoadEmbeddedWordRepo(bytes []byte) (WordRepository, error) {
 	wr := WordRepository{}
 
@@ -59,6 +289,29 @@ func LoadEmbeddedWordRepo(bytes []byte) (WordRepository, error) {
 	if err != nil {
 		return WordRepository{}, err
 	}
+	wr.normalize()
 
 	return wr, nil
 }
+
+// DataDir is where the app keeps its files, following the XDG base directory spec.
+func DataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", ".local/share")
+}
+
+// ConfigDir is where the app looks for its config, following the XDG base directory spec.
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+func xdgDir(env, fallback string) (string, error) {
+	base := os.Getenv(env)
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, fallback)
+	}
+	return filepath.Join(base, "wohrdle"), nil
+}
@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -48,3 +52,125 @@ func TestLoadWordRepoFromJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadEmbeddedWordRepoPools(t *testing.T) {
+	testCases := []struct {
+		name    string
+		json    string
+		answers []string
+		allowed []string
+	}{
+		{"legacy", `{"Words": {"4": ["test", "work"]}}`, []string{"test", "work"}, []string{"test", "work"}},
+		{"split", `{"Answers": {"4": ["test"]}, "Allowed": {"4": ["test", "tost"]}}`, []string{"test"}, []string{"test", "tost"}},
+		{"answersOnly", `{"Answers": {"4": ["test"]}}`, []string{"test"}, []string{"test"}},
+		{"answersAreAllowed", `{"Answers": {"4": ["test"]}, "Allowed": {"4": ["tost"]}}`, []string{"test"}, []string{"tost", "test"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wr, err := LoadEmbeddedWordRepo([]byte(tc.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(wr.Answers["4"], tc.answers) {
+				t.Fatalf("unexpected answers. got=%v, expected=%v", wr.Answers["4"], tc.answers)
+			}
+			if !slices.Equal(wr.Allowed["4"], tc.allowed) {
+				t.Fatalf("unexpected allowed. got=%v, expected=%v", wr.Allowed["4"], tc.allowed)
+			}
+		})
+	}
+}
+
+func TestLoadWordRepoFromText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	os.WriteFile(path, []byte("# a comment\nTest\nwork\n\n  tests  \ntest\n"), 0o644)
+
+	wr, err := LoadWordRepoFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(wr.Answers["4"], []string{"test", "work"}) || !slices.Equal(wr.Allowed["5"], []string{"tests"}) {
+		t.Fatalf("unexpected buckets. got=%v", wr.Answers)
+	}
+}
+
+func TestLoadWordRepoErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		contents string
+		expected string
+	}{
+		{"textNotALetter", "words.txt", "test\nwo-rk\n", `words.txt:2: "wo-rk" contains '-', which is not a letter`},
+		{"textEmpty", "words.txt", "# nothing\n", "words.txt: no words found"},
+		{"jsonSyntax", "words.json", `{"Words": {"4": ["test"]`, "words.json: unexpected EOF"},
+		{"jsonUnknownField", "words.json", `{"Wrods": {"4": ["test"]}}`, `words.json: json: unknown field "Wrods"`},
+		{"jsonBadKey", "words.json", `{"Words": {"four": ["test"]}}`, `words.json: Words: key "four" is not a word length`},
+		{"jsonWrongLength", "words.json", `{"Answers": {"4": ["test", "tests"]}}`, `words.json: Answers["4"][1]: "tests" has length 5`},
+		{"jsonUpperCase", "words.json", `{"Allowed": {"4": ["TEST"]}}`, `words.json: Allowed["4"][0]: "TEST" must be lower-case`},
+		{"jsonEmpty", "words.json", `{}`, "words.json: no words found"},
+		{"jsonNoAnswers", "words.json", `{"Answers": {}, "Allowed": {"4": ["test"]}}`, "words.json: Answers: no words found"},
+		{"jsonEmptyAnswers", "words.json", `{"Answers": {"4": []}, "Allowed": {"4": ["test"]}}`, "words.json: Answers: no words found"},
+		{"jsonNotInAlphabet", "words.json", `{"Alphabet": "ABCDEFGHIJKLMNOPQRSTUVWXYZ", "Words": {"4": ["niño"]}}`,
+			`words.json: Words["4"][0]: "niño" contains 'Ñ', which is not in the alphabet`},
+		{"jsonFoldingNotALetter", "words.json", `{"Folding": {"É": "EE"}, "Words": {"4": ["test"]}}`, `words.json: Folding: "EE" is not an upper-case letter`},
+		{"jsonFoldingNotInAlphabet", "words.json", `{"Alphabet": "ABC", "Folding": {"É": "A"}, "Words": {"4": ["abba"]}}`, `words.json: Folding: "É" is not in the alphabet`},
+		{"jsonFoldingChained", "words.json", `{"Folding": {"Ê": "É", "É": "E"}, "Words": {"4": ["test"]}}`, `words.json: Folding: "Ê" folds to "É", which is folded too`},
+		{"jsonLowerCaseAlphabet", "words.json", `{"Alphabet": "abc", "Words": {"4": ["abba"]}}`, `words.json: Alphabet: 'a' is not an upper-case letter`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tc.file)
+			os.WriteFile(path, []byte(tc.contents), 0o644)
+
+			_, err := LoadWordRepoFromFile(path)
+			if err == nil {
+				t.Fatal("invalid word list was accepted")
+			}
+			if got := strings.TrimPrefix(err.Error(), dir+string(filepath.Separator)); got != tc.expected {
+				t.Fatalf("unexpected error.\ngot=     %s\nexpected=%s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	testCases := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{"default", `{"Words": {"4": ["test"]}}`, DEFAULT_ALPHABET},
+		{"extraLetters", `{"Words": {"4": ["niño"], "2": ["öl"]}}`, DEFAULT_ALPHABET + "ÑÖ"},
+		{"given", `{"Alphabet": "ABCÑ", "Words": {"4": ["abba"]}}`, "ABCÑ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wr, err := LoadEmbeddedWordRepo([]byte(tc.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wr.Alphabet != tc.expected {
+				t.Fatalf("unexpected alphabet. got=%q, expected=%q", wr.Alphabet, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFoldingTable(t *testing.T) {
+	wr, err := LoadEmbeddedWordRepo([]byte(`{"Folding": {"É": "E", "Ã": "A"}, "Words": {"4": ["café"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := wr.FoldingTable()
+	if len(table) != 2 || table['É'] != 'E' || table['Ã'] != 'A' {
+		t.Fatalf("unexpected folding table. got=%q", table)
+	}
+	if table := NewWordRepository(map[string][]string{"4": {"test"}}).FoldingTable(); table != nil {
+		t.Fatalf("folding table without any folding. got=%q", table)
+	}
+}
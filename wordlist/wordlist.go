@@ -0,0 +1,224 @@
+package wordlist
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+// Entry is one line of a raw word list: a word optionally followed by how often it is used.
+type Entry struct {
+	Word      string
+	Frequency int
+}
+
+// Filter decides which entries make it into a built word list.
+type Filter struct {
+	MinLen int
+	MaxLen int // no limit when 0
+
+	ExcludeProperNouns bool // any upper-case letter
+	ExcludeApostrophes bool
+	ExcludeAccented    bool   // any letter outside of a-z
+	Alphabet           string // the upper-case letters words may use, in place of a-z. any when empty
+
+	Deny         map[string]bool
+	MinFrequency int // entries without a frequency count as 0
+}
+
+// Read parses a raw word list. Each line holds a word and, optionally, a frequency separated
+// by whitespace. Blank lines and lines starting with '#' are skipped.
+func Read(r io.Reader) ([]Entry, error) {
+	entries := []Entry{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		entry := Entry{Word: fields[0]}
+		switch len(fields) {
+		case 1:
+		case 2:
+			freq, err := strconv.Atoi(fields[1])
+			if err != nil || freq < 0 {
+				return nil, fmt.Errorf("line %d: frequency %q is not a whole number", line, fields[1])
+			}
+			entry.Frequency = freq
+		default:
+			return nil, fmt.Errorf("line %d: expected a word and an optional frequency, got %d fields", line, len(fields))
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ReadWordSet reads one word per line into a set, such as a deny list. Words are lower-cased.
+func ReadWordSet(r io.Reader) (map[string]bool, error) {
+	entries, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	for _, entry := range entries {
+		set[strings.ToLower(entry.Word)] = true
+	}
+	return set, nil
+}
+
+// Keep reports whether the entry passes the filter.
+func (f Filter) Keep(entry Entry) bool {
+	if f.ExcludeApostrophes && strings.ContainsRune(entry.Word, '\'') {
+		return false
+	}
+	word := stripApostrophes(entry.Word)
+	length := len([]rune(word))
+	if length < max(f.MinLen, 1) || (f.MaxLen > 0 && length > f.MaxLen) {
+		return false
+	}
+	if entry.Frequency < f.MinFrequency {
+		return false
+	}
+	if f.Deny[strings.ToLower(word)] {
+		return false
+	}
+	for _, r := range word {
+		switch {
+		case f.ExcludeProperNouns && unicode.IsUpper(r):
+			return false
+		case f.Alphabet != "" && !strings.ContainsRune(f.Alphabet, unicode.ToUpper(r)):
+			return false
+		case f.Alphabet == "" && f.ExcludeAccented && !isPlainLetter(unicode.ToLower(r)):
+			return false
+		case !unicode.IsLetter(r) || !utils.RuneIsAlpha(r):
+			// nothing else can be typed in the game
+			return false
+		}
+	}
+	return true
+}
+
+// stripApostrophes turns contractions like "o'clock" into playable words
+func stripApostrophes(word string) string {
+	return strings.ReplaceAll(word, "'", "")
+}
+
+func isPlainLetter(r rune) bool {
+	return 'a' <= r && r <= 'z'
+}
+
+// Build filters, lower-cases and dedups entries, bucketing them by length. Every bucket is sorted.
+func Build(entries []Entry, f Filter) map[string][]string {
+	seen := map[string]bool{}
+	words := map[string][]string{}
+	for _, entry := range entries {
+		if !f.Keep(entry) {
+			continue
+		}
+		word := strings.ToLower(stripApostrophes(entry.Word))
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		length := strconv.Itoa(len([]rune(word)))
+		words[length] = append(words[length], word)
+	}
+	for _, bucket := range words {
+		slices.Sort(bucket)
+	}
+	return words
+}
+
+// Answers are the built words that are also in pool, bucketed by length like the words.
+// Lengths without any are left out.
+func Answers(words map[string][]string, pool map[string]bool) map[string][]string {
+	answers := map[string][]string{}
+	for length, bucket := range words {
+		for _, word := range bucket {
+			if pool[word] {
+				answers[length] = append(answers[length], word)
+			}
+		}
+	}
+	return answers
+}
+
+// WriteJSON writes the repository in the same shape as the embedded ones. Map keys are sorted
+// by encoding/json, so the output is deterministic.
+func WriteJSON(w io.Writer, wr utils.WordRepository) error {
+	b, err := json.MarshalIndent(wr, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// ParseFolding reads comma separated pairs of an accented letter and the plain letter it is
+// typed as, such as "áa,ée", into the Folding of a repository. Letters are upper-cased.
+func ParseFolding(s string) (map[string]string, error) {
+	folding := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		letters := []rune(strings.ToUpper(strings.TrimSpace(pair)))
+		if len(letters) != 2 || !unicode.IsLetter(letters[0]) || !unicode.IsLetter(letters[1]) {
+			return nil, fmt.Errorf("fold pair %q is not two letters", pair)
+		}
+		folding[string(letters[0])] = string(letters[1])
+	}
+	return folding, nil
+}
+
+// WriteText writes one word per line, shortest words first.
+func WriteText(w io.Writer, words map[string][]string) error {
+	bw := bufio.NewWriter(w)
+	for _, length := range Lengths(words) {
+		for _, word := range words[strconv.Itoa(length)] {
+			if _, err := bw.WriteString(word + "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Lengths are the word lengths present in words, in ascending order.
+func Lengths(words map[string][]string) []int {
+	lengths := []int{}
+	for key := range words {
+		length, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		lengths = append(lengths, length)
+	}
+	slices.Sort(lengths)
+	return lengths
+}
+
+// WriteStats prints how many answers and allowed words there are of each length.
+func WriteStats(w io.Writer, wr utils.WordRepository) error {
+	tw := bufio.NewWriter(w)
+	fmt.Fprintf(tw, "%6s %8s %8s\n", "length", "answers", "allowed")
+	totalAnswers, totalAllowed := 0, 0
+	for _, length := range Lengths(wr.Allowed) {
+		key := strconv.Itoa(length)
+		answers, allowed := len(wr.Answers[key]), len(wr.Allowed[key])
+		totalAnswers += answers
+		totalAllowed += allowed
+		fmt.Fprintf(tw, "%6d %8d %8d\n", length, answers, allowed)
+	}
+	fmt.Fprintf(tw, "%6s %8d %8d\n", "total", totalAnswers, totalAllowed)
+	return tw.Flush()
+}
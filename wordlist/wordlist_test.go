@@ -0,0 +1,150 @@
+package wordlist
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitlab.com/daneofmanythings/wohrdle/utils"
+)
+
+const rawList string = `# a raw list
+test 50
+Test 40
+Boston 30
+o'clock 20
+clientèle 10
+work
+tests 5
+test 1
+`
+
+func TestRead(t *testing.T) {
+	entries, err := Read(strings.NewReader(rawList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 8 || entries[0] != (Entry{"test", 50}) || entries[5] != (Entry{"work", 0}) {
+		t.Fatalf("unexpected entries. got=%v", entries)
+	}
+
+	if _, err := Read(strings.NewReader("test fifty\n")); err == nil {
+		t.Fatal("a bad frequency was accepted")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	entries, _ := Read(strings.NewReader(rawList))
+	testCases := []struct {
+		name     string
+		filter   Filter
+		expected map[string][]string
+	}{
+		{
+			"defaults",
+			Filter{ExcludeProperNouns: true, ExcludeApostrophes: true, ExcludeAccented: true},
+			map[string][]string{"4": {"test", "work"}, "5": {"tests"}},
+		},
+		{
+			"keepEverything",
+			Filter{},
+			map[string][]string{"4": {"test", "work"}, "5": {"tests"}, "6": {"boston", "oclock"}, "9": {"clientèle"}},
+		},
+		{
+			"lengths",
+			Filter{MinLen: 5, MaxLen: 6, ExcludeProperNouns: true},
+			map[string][]string{"5": {"tests"}, "6": {"oclock"}},
+		},
+		{
+			"frequency",
+			Filter{MinFrequency: 20},
+			map[string][]string{"4": {"test"}, "6": {"boston", "oclock"}},
+		},
+		{
+			"deny",
+			Filter{Deny: map[string]bool{"test": true, "boston": true}, ExcludeAccented: true},
+			map[string][]string{"4": {"work"}, "5": {"tests"}, "6": {"oclock"}},
+		},
+		{
+			"alphabet",
+			Filter{Alphabet: "CEILNTÈ", ExcludeAccented: true},
+			map[string][]string{"9": {"clientèle"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Build(entries, tc.filter)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("unexpected buckets. got=%v, expected=%v", got, tc.expected)
+			}
+			for length := range tc.expected {
+				if !slices.Equal(got[length], tc.expected[length]) {
+					t.Fatalf("unexpected words of length %s. got=%v, expected=%v", length, got[length], tc.expected[length])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteIsDeterministic(t *testing.T) {
+	words := map[string][]string{"10": {"abandoning"}, "4": {"test", "work"}, "5": {"tests"}}
+
+	text := bytes.Buffer{}
+	WriteText(&text, words)
+	if text.String() != "test\nwork\ntests\nabandoning\n" {
+		t.Fatalf("unexpected text output. got=%q", text.String())
+	}
+
+	first, second := bytes.Buffer{}, bytes.Buffer{}
+	WriteJSON(&first, utils.WordRepository{Words: words})
+	WriteJSON(&second, utils.WordRepository{Words: words})
+	if first.String() != second.String() {
+		t.Fatal("json output is not deterministic")
+	}
+	wr, err := utils.LoadEmbeddedWordRepo(first.Bytes())
+	if err != nil || !slices.Equal(wr.Answers["4"], words["4"]) {
+		t.Fatalf("json output could not be loaded. err=%v", err)
+	}
+}
+
+func TestAnswers(t *testing.T) {
+	words := map[string][]string{"4": {"test", "work"}, "5": {"tests", "toast"}}
+	pool, err := ReadWordSet(strings.NewReader("Toast\nwork\nabsent\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answers := Answers(words, pool)
+	if len(answers) != 2 || !slices.Equal(answers["4"], []string{"work"}) || !slices.Equal(answers["5"], []string{"toast"}) {
+		t.Fatalf("unexpected answers. got=%v", answers)
+	}
+}
+
+func TestParseFolding(t *testing.T) {
+	folding, err := ParseFolding("áa, Éé,ée")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folding) != 2 || folding["Á"] != "A" || folding["É"] != "E" {
+		t.Fatalf("unexpected folding. got=%v", folding)
+	}
+	if _, err := ParseFolding("áa,é"); err == nil || err.Error() != `fold pair "é" is not two letters` {
+		t.Fatalf("a single letter was accepted. err=%v", err)
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	wr := utils.WordRepository{
+		Answers: map[string][]string{"4": {"test"}},
+		Allowed: map[string][]string{"4": {"test", "work"}, "5": {"tests"}},
+	}
+	out := bytes.Buffer{}
+	WriteStats(&out, wr)
+
+	expected := "length  answers  allowed\n     4        1        2\n     5        0        1\n total        1        3\n"
+	if out.String() != expected {
+		t.Fatalf("unexpected stats.\ngot=\n%s\nexpected=\n%s", out.String(), expected)
+	}
+}